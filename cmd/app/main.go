@@ -1,8 +1,10 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
 	_ "github.com/AntonTsoy/subscription-service/docs"
@@ -13,10 +15,12 @@ import (
 
 	"github.com/AntonTsoy/subscription-service/internal/config"
 	"github.com/AntonTsoy/subscription-service/internal/database"
+	"github.com/AntonTsoy/subscription-service/internal/logger"
 	"github.com/AntonTsoy/subscription-service/internal/repository"
 	"github.com/AntonTsoy/subscription-service/internal/service"
+	"github.com/AntonTsoy/subscription-service/internal/transport/admin"
 	"github.com/AntonTsoy/subscription-service/internal/transport/handler"
-	"github.com/AntonTsoy/subscription-service/internal/transport/logger"
+	"github.com/AntonTsoy/subscription-service/internal/transport/idempotency"
 )
 
 // @title           Subscription Service API
@@ -25,38 +29,77 @@ import (
 // @BasePath        /
 // @host            localhost:8080
 // @schemes         http
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Токен администратора в формате "Bearer <token>"
 func main() {
 	cfg := config.Load()
 
+	log := logger.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	slog.SetDefault(log)
+	for _, env := range cfg.InvalidEnv {
+		log.Warn("неверное значение переменной окружения", "key", env.Key, "value", env.Value, "default", env.Default)
+	}
+	if cfg.AdminToken == "" {
+		log.Warn("ADMIN_TOKEN не задан, изменение курсов валют недоступно")
+	}
+
 	db, err := database.New(cfg)
 	if err != nil {
-		log.Fatalf("ошибка базы данных: %v", err)
+		log.Error("ошибка базы данных", "error", err)
+		os.Exit(1)
 	}
 	defer db.Close()
 
 	if err = db.HealthCheck(); err != nil {
-		log.Fatalf("не удалось открыть соединение c базой данных: %v", err)
+		log.Error("не удалось открыть соединение c базой данных", "error", err)
+		os.Exit(1)
 	}
 
 	subsRepo := repository.NewSubsRepo(db.DB())
 
+	go idempotency.RunCleanup(context.Background(), subsRepo, time.Hour)
+
 	subsService := service.NewSubsService(subsRepo)
 
 	subsHandler := handler.NewSubsHandler(subsService)
 
 	r := chi.NewRouter()
-	r.Use(logger.Logger)
+	r.Use(logger.Middleware(log))
+	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(10 * time.Second))
 
-	r.Post("/subscriptions", subsHandler.CreateSubscription)
+	idempotent := r.With(idempotency.Middleware(subsRepo, cfg.IdempotencyTTL))
+	idempotent.Post("/subscriptions", subsHandler.CreateSubscription)
+	idempotent.Post("/subscriptions/batch", subsHandler.BatchSubscriptions)
+	r.Get("/subscriptions/forecast", subsHandler.ForecastSubscriptionsCost)
 	r.Get("/subscriptions/{id}", subsHandler.GetSubscription)
 	r.Get("/subscriptions", subsHandler.GetAllSubscriptions)
 	r.Put("/subscriptions/{id}", subsHandler.UpdateSubscription)
+	r.Patch("/subscriptions/{id}", subsHandler.PatchSubscription)
 	r.Delete("/subscriptions/{id}", subsHandler.DeleteSubscription)
+	r.Post("/subscriptions/{id}/pause", subsHandler.PauseSubscription)
+	r.Post("/subscriptions/{id}/resume", subsHandler.ResumeSubscription)
+	r.Post("/subscriptions/{id}/cancel", subsHandler.CancelSubscription)
+	r.Post("/subscriptions/{id}/pauses", subsHandler.AddSubscriptionPause)
+	r.Get("/subscriptions/{id}/pauses", subsHandler.GetSubscriptionPauses)
+	r.Delete("/subscriptions/{id}/pauses/{pauseId}", subsHandler.DeleteSubscriptionPause)
+	r.Post("/subscriptions/{id}/prices", subsHandler.AddSubscriptionPrice)
+	r.Get("/subscriptions/{id}/prices", subsHandler.GetSubscriptionPrices)
+	r.Post("/subscriptions/{id}/discounts", subsHandler.AddSubscriptionDiscount)
+	r.Get("/subscriptions/{id}/discounts", subsHandler.GetSubscriptionDiscounts)
 	r.Get("/subscriptions/{start}/{end}/total-cost", subsHandler.TotalServiceSubscriptionsCost)
+	r.Get("/subscriptions/{start}/{end}/monthly-cost", subsHandler.MonthlyServiceSubscriptionsCost)
+
+	r.Get("/admin/exchange-rates", subsHandler.GetExchangeRates)
+	r.With(admin.RequireToken(cfg.AdminToken)).Put("/admin/exchange-rates/{currency}/{month}", subsHandler.SaveExchangeRate)
+	r.With(admin.RequireToken(cfg.AdminToken)).Delete("/admin/exchange-rates/{currency}/{month}", subsHandler.DeleteExchangeRate)
 
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 
-	log.Println("Server started at http://localhost:8080/swagger/index.html")
-	http.ListenAndServe(":8080", r)
+	log.Info("сервер запущен", "swagger", "http://localhost:8080/swagger/index.html")
+	if err := http.ListenAndServe(":8080", r); err != nil {
+		log.Error("сервер остановлен", "error", err)
+	}
 }
@@ -16,9 +16,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/subscriptions": {
+        "/admin/exchange-rates": {
             "get": {
-                "description": "Возвращает список всех подписок с поддержкой пагинации",
+                "description": "Возвращает курсы валют к рублю с фильтрацией по валюте и периоду",
                 "consumes": [
                     "application/json"
                 ],
@@ -26,43 +26,62 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "exchange-rates"
                 ],
-                "summary": "Список подписок",
+                "summary": "Список курсов валют",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Максимальное количество элементов (по умолчанию 100)",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "Код валюты ISO 4217 (опционально)",
+                        "name": "currency",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Смещение от начала (по умолчанию 0)",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "Первый месяц (MM-YYYY, опционально)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Последний месяц (MM-YYYY, опционально)",
+                        "name": "to",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список подписок",
+                        "description": "Курсы валют",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.SubscriptionResponse"
+                                "$ref": "#/definitions/dto.ExchangeRateResponse"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
                     "500": {
-                        "description": "Ошибка при получении списка подписок",
+                        "description": "Ошибка при получении курсов",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Создаёт новую подписку для пользователя",
+            }
+        },
+        "/admin/exchange-rates/{currency}/{month}": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Создаёт или заменяет курс валюты к рублю на месяц",
                 "consumes": [
                     "application/json"
                 ],
@@ -70,45 +89,128 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "exchange-rates"
                 ],
-                "summary": "Создать подписку",
+                "summary": "Задать курс валюты",
                 "parameters": [
                     {
-                        "description": "Данные новой подписки",
+                        "type": "string",
+                        "description": "Код валюты ISO 4217",
+                        "name": "currency",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Месяц действия курса (MM-YYYY)",
+                        "name": "month",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Курс: сколько рублей стоит единица валюты",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.SubscriptionRequest"
+                            "$ref": "#/definitions/dto.ExchangeRateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Созданная подписка",
+                    "200": {
+                        "description": "Сохранённый курс",
                         "schema": {
-                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                            "$ref": "#/definitions/dto.ExchangeRateResponse"
                         }
                     },
                     "400": {
                         "description": "Некорректные данные запроса",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Нет токена администратора или он неверный",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при создании подписки",
+                        "description": "Ошибка при сохранении курса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Удаляет курс валюты на месяц",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "exchange-rates"
+                ],
+                "summary": "Удалить курс валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код валюты ISO 4217",
+                        "name": "currency",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Месяц действия курса (MM-YYYY)",
+                        "name": "month",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Курс успешно удалён"
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Нет токена администратора или он неверный",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Курс не найден",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении курса",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
             }
         },
-        "/subscriptions/{id}": {
+        "/subscriptions": {
             "get": {
-                "description": "Возвращает информацию о подписке по её ID",
+                "description": "Возвращает список подписок с фильтрами, сортировкой и пагинацией",
                 "consumes": [
                     "application/json"
                 ],
@@ -118,45 +220,105 @@ const docTemplate = `{
                 "tags": [
                     "subscriptions"
                 ],
-                "summary": "Получить подписку",
+                "summary": "Список подписок",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Максимальное количество элементов (по умолчанию 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение от начала (по умолчанию 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса (точное совпадение)",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало названия сервиса",
+                        "name": "service_name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная цена",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная цена",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Месяц, в котором подписка действует (MM-YYYY)",
+                        "name": "active_on",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только бессрочные подписки",
+                        "name": "open_ended",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор страницы из next_cursor. С этим параметром (в том числе пустым) ответ — dto.SubscriptionListResponse, offset не используется",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть dto.SubscriptionListResponse с общим числом подписок и ссылкой на следующую страницу",
+                        "name": "envelope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "price,-start_date",
+                        "description": "Поля сортировки через запятую, - для убывания: id, price, start_date, end_date, service_name, user_id",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Подписка",
+                        "description": "Страница подписок (с envelope=true или cursor). Без этих параметров ответ — массив dto.SubscriptionResponse",
                         "schema": {
-                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                            "$ref": "#/definitions/dto.SubscriptionListResponse"
                         }
                     },
                     "400": {
-                        "description": "Некорректный ID",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "404": {
-                        "description": "Подписка не найдена",
+                        "description": "Некорректные параметры запроса",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при получении подписки",
+                        "description": "Ошибка при получении списка подписок",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Обновляет данные подписки по её ID",
+            "post": {
+                "description": "Создаёт новую подписку для пользователя",
                 "consumes": [
                     "application/json"
                 ],
@@ -166,17 +328,16 @@ const docTemplate = `{
                 "tags": [
                     "subscriptions"
                 ],
-                "summary": "Обновить подписку",
+                "summary": "Создать подписку",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Ключ идемпотентности: повтор с тем же ключом вернёт сохранённый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     },
                     {
-                        "description": "Обновлённые данные подписки",
+                        "description": "Данные новой подписки",
                         "name": "request",
                         "in": "body",
                         "required": true,
@@ -186,31 +347,48 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Подписка успешно обновлена"
+                    "201": {
+                        "description": "Созданная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Некорректные данные запроса",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Подписка не найдена",
+                    "409": {
+                        "description": "Запрос с этим Idempotency-Key ещё выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушения правил валидации полей (или Idempotency-Key уже использован с другим запросом)",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.ValidationError"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при обновлении подписки",
+                        "description": "Ошибка при создании подписки",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Удаляет подписку по её ID",
+            }
+        },
+        "/subscriptions/batch": {
+            "post": {
+                "description": "Выполняет до 1000 операций create/update/delete в одной транзакции.\nВ режиме atomic (по умолчанию) ошибка любой операции отменяет весь пакет: ответ получает статус упавшей операции, остальные операции — 424.\nВ режиме partial каждая операция применяется независимо, ответ — 200 со статусом каждой операции.",
                 "consumes": [
                     "application/json"
                 ],
@@ -220,44 +398,67 @@ const docTemplate = `{
                 "tags": [
                     "subscriptions"
                 ],
-                "summary": "Удалить подписку",
+                "summary": "Пакетные операции над подписками",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Ключ идемпотентности: повтор с тем же ключом вернёт сохранённый ответ",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Режим и операции пакета",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Подписка успешно удалена"
+                    "200": {
+                        "description": "Результаты операций",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchResponse"
+                        }
                     },
                     "400": {
-                        "description": "Некорректный ID",
+                        "description": "Некорректный пакет",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     },
                     "404": {
-                        "description": "Подписка не найдена",
+                        "description": "Пакет отменён: подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Пакет отменён: версия подписки не совпадает",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.BatchResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Пакет отменён: операция не прошла валидацию (нарушения в results[].errors)",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BatchResponse"
                         }
                     },
                     "500": {
-                        "description": "Ошибка при удалении подписки",
+                        "description": "Ошибка при выполнении пакета",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
             }
         },
-        "/subscriptions/{start}/{end}/total-cost": {
+        "/subscriptions/forecast": {
             "get": {
-                "description": "Считает суммарную стоимость подписок пользователя на конкретный сервис за период [start; end]",
+                "description": "Прогнозирует стоимость подписок по месяцам на months месяцев вперёд, начиная со следующего месяца\nБессрочные подписки считаются продолжающимися, завершённые — заканчиваются в end_date\nДля будущих месяцев без курса валюты используется последний известный курс",
                 "consumes": [
                     "application/json"
                 ],
@@ -267,21 +468,13 @@ const docTemplate = `{
                 "tags": [
                     "subscriptions"
                 ],
-                "summary": "Общая стоимость подписок",
+                "summary": "Прогноз расходов на подписки",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Начало периода (MM-YYYY)",
-                        "name": "start",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Конец периода (MM-YYYY)",
-                        "name": "end",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Количество месяцев прогноза, от 1 до 120 (по умолчанию 12)",
+                        "name": "months",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -294,28 +487,1144 @@ const docTemplate = `{
                         "description": "Название сервиса (опционально)",
                         "name": "service_name",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Суммарная стоимость подписок",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer"
-                            }
-                        }
-                    },
-                    "400": {
-                        "description": "Некорректные параметры запроса",
-                        "schema": {
-                            "type": "string"
-                        }
+                    },
+                    {
+                        "enum": [
+                            "weekly",
+                            "monthly",
+                            "quarterly",
+                            "yearly"
+                        ],
+                        "type": "string",
+                        "description": "Период оплаты подписок (опционально)",
+                        "name": "billing_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Считать цены в месячном эквиваленте вместо фактических списаний (по умолчанию false)",
+                        "name": "normalize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "full",
+                            "daily",
+                            "renewal"
+                        ],
+                        "type": "string",
+                        "description": "Начисление за неполные месяцы подписок с датами до дня (по умолчанию full)",
+                        "name": "proration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, код ISO 4217 (по умолчанию RUB)",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Прогноз стоимости по месяцам и итог",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ForecastResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Нет курсов валют для пересчёта",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при вычислении прогноза",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "description": "Возвращает информацию о подписке по её ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки для If-Match"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении подписки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Обновляет данные подписки по её ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Обновить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из GET; без заголовка версия не проверяется",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Обновлённые данные подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка успешно обновлена",
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена: ETag не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушения правил валидации полей",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при обновлении подписки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет подписку по её ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Удалить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из GET; без заголовка версия не проверяется",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка успешно удалена"
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена: ETag не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении подписки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Применяет JSON Merge Patch (RFC 7386): меняются только переданные поля, null удаляет необязательное поле (например, \"end_date\": null делает подписку бессрочной)",
+                "consumes": [
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Частично обновить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из GET; без заголовка версия не проверяется",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Изменяемые поля подписки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновлённая подписка",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный патч",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Подписка изменена: ETag не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Тип содержимого не application/merge-patch+json",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Нарушения правил валидации полей подписки после патча",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при обновлении подписки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/cancel": {
+            "post": {
+                "description": "Переводит действующую или приостановленную подписку в статус cancelled, end_date переносится на текущий день (месяц)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "status"
+                ],
+                "summary": "Отменить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка после смены статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Недопустимая смена статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при смене статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/discounts": {
+            "get": {
+                "description": "Возвращает все скидки подписки в порядке начала действия",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discounts"
+                ],
+                "summary": "Скидки подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Скидки подписки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriptionDiscountResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении скидок",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет процентную или фиксированную скидку, действующую с месяца start_month по end_month включительно",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discounts"
+                ],
+                "summary": "Добавить скидку на подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тип, размер и месяцы действия скидки (MM-YYYY)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionDiscountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Добавленная скидка",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionDiscountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Скидка начинается вне периода подписки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при добавлении скидки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pause": {
+            "post": {
+                "description": "Переводит действующую подписку в статус paused. Месяцы со следующего и до возобновления не оплачиваются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "status"
+                ],
+                "summary": "Приостановить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка после смены статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Недопустимая смена статуса или пересечение с другой паузой",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при смене статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pauses": {
+            "get": {
+                "description": "Возвращает все паузы подписки в порядке начала. У текущей паузы нет end_month",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pauses"
+                ],
+                "summary": "Паузы подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Паузы подписки",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriptionPauseResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении пауз",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Замораживает подписку на месяцы [start_month; end_month], они не оплачиваются\nПауза должна попадать в период подписки и не пересекаться с другими паузами",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pauses"
+                ],
+                "summary": "Добавить паузу подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Первый и последний месяц паузы (MM-YYYY)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionPauseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Добавленная пауза",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionPauseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Пауза пересекается с другой паузой",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Пауза вне периода подписки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при добавлении паузы",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pauses/{pauseId}": {
+            "delete": {
+                "description": "Удаляет паузу с end_month. Текущая пауза без end_month снимается через resume",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pauses"
+                ],
+                "summary": "Удалить паузу подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID паузы",
+                        "name": "pauseId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пауза удалена"
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Пауза не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Пауза ещё не закончилась",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при удалении паузы",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/prices": {
+            "get": {
+                "description": "Возвращает все изменения цены подписки в порядке вступления в силу",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "История цен подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "История цен",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SubscriptionPriceResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при получении истории цен",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Добавляет новую цену подписки, действующую начиная с месяца effective_from. Прошлые месяцы считаются по прежней цене. Цена на уже заданный месяц заменяется",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Изменить цену подписки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новая цена и месяц начала её действия (MM-YYYY)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Цена на этот месяц заменена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionPriceResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Добавленное изменение цены",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionPriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные данные запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Месяц изменения цены вне периода подписки",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при изменении цены",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/resume": {
+            "post": {
+                "description": "Возвращает приостановленную подписку в статус active, оплата возобновляется с текущего месяца",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "status"
+                ],
+                "summary": "Возобновить подписку",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка после смены статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный ID",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Недопустимая смена статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при смене статуса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{start}/{end}/monthly-cost": {
+            "get": {
+                "description": "Возвращает стоимость подписок по каждому месяцу периода [start; end] с теми же фильтрами, что и общая стоимость",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Помесячная стоимость подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (MM-YYYY)",
+                        "name": "start",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (MM-YYYY)",
+                        "name": "end",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя (опционально)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса (опционально)",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "weekly",
+                            "monthly",
+                            "quarterly",
+                            "yearly"
+                        ],
+                        "type": "string",
+                        "description": "Период оплаты подписок (опционально)",
+                        "name": "billing_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Считать цены в месячном эквиваленте вместо фактических списаний (по умолчанию false)",
+                        "name": "normalize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "full",
+                            "daily",
+                            "renewal"
+                        ],
+                        "type": "string",
+                        "description": "Начисление за неполные месяцы подписок с датами до дня (по умолчанию full)",
+                        "name": "proration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, код ISO 4217 (по умолчанию RUB)",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Стоимость подписок по месяцам",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.MonthlyCostResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса или период длиннее 120 месяцев",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Нет курсов валют для пересчёта",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка при вычислении стоимости",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{start}/{end}/total-cost": {
+            "get": {
+                "description": "Считает суммарную стоимость подписок пользователя на конкретный сервис за период [start; end]\nПри указании group_by возвращает итоги по каждой группе и общую сумму",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Общая стоимость подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало периода (MM-YYYY)",
+                        "name": "start",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (MM-YYYY)",
+                        "name": "end",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя (опционально)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса (опционально)",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "weekly",
+                            "monthly",
+                            "quarterly",
+                            "yearly"
+                        ],
+                        "type": "string",
+                        "description": "Период оплаты подписок (опционально)",
+                        "name": "billing_period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Считать цены в месячном эквиваленте вместо фактических списаний (по умолчанию false)",
+                        "name": "normalize",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "full",
+                            "daily",
+                            "renewal"
+                        ],
+                        "type": "string",
+                        "description": "Начисление за неполные месяцы подписок с датами до дня (по умолчанию full)",
+                        "name": "proration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Валюта результата, код ISO 4217 (по умолчанию RUB)",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "service_name",
+                            "user_id"
+                        ],
+                        "type": "string",
+                        "description": "Группировка итогов: service_name или user_id (опционально)",
+                        "name": "group_by",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Суммарная стоимость подписок (без group_by) или dto.GroupedCostResponse (с group_by)",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TotalCostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Нет курсов валют для пересчёта",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Problem"
+                        }
                     },
                     "500": {
                         "description": "Ошибка при вычислении стоимости",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/dto.Problem"
                         }
                     }
                 }
@@ -323,9 +1632,362 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "dto.BatchOperationRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "subscription": {
+                    "$ref": "#/definitions/dto.SubscriptionRequest"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "default": "atomic",
+                    "enum": [
+                        "atomic",
+                        "partial"
+                    ]
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchOperationRequest"
+                    }
+                }
+            }
+        },
+        "dto.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchResultResponse"
+                    }
+                }
+            }
+        },
+        "dto.BatchResultResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "subscription": {
+                    "$ref": "#/definitions/dto.SubscriptionResponse"
+                }
+            }
+        },
+        "dto.ExchangeRateRequest": {
+            "type": "object",
+            "properties": {
+                "rate": {
+                    "type": "number",
+                    "example": 92.5
+                }
+            }
+        },
+        "dto.ExchangeRateResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "dto.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "min"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "price"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "price must not be negative"
+                }
+            }
+        },
+        "dto.ForecastResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "months": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MonthlyCostResponse"
+                    }
+                },
+                "totalCost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.MissingRateResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "month": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MonthlyCostResponse": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "type": "integer"
+                },
+                "discount": {
+                    "type": "integer"
+                },
+                "gross": {
+                    "type": "integer"
+                },
+                "month": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "SUBSCRIPTION_NOT_FOUND"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "subscription 42 not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/subscriptions/42"
+                },
+                "missing_rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MissingRateResponse"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Subscription not found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/problems/subscription-not-found"
+                }
+            }
+        },
+        "dto.SubscriptionDiscountRequest": {
+            "type": "object",
+            "properties": {
+                "end_month": {
+                    "type": "string",
+                    "example": "09-2025"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "percent",
+                        "fixed"
+                    ]
+                },
+                "start_month": {
+                    "type": "string",
+                    "example": "07-2025"
+                },
+                "value": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "dto.SubscriptionDiscountResponse": {
+            "type": "object",
+            "properties": {
+                "end_month": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "start_month": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SubscriptionListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SubscriptionResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SubscriptionPauseRequest": {
+            "type": "object",
+            "properties": {
+                "end_month": {
+                    "type": "string",
+                    "example": "08-2025"
+                },
+                "start_month": {
+                    "type": "string",
+                    "example": "07-2025"
+                }
+            }
+        },
+        "dto.SubscriptionPauseResponse": {
+            "type": "object",
+            "properties": {
+                "end_month": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start_month": {
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SubscriptionPriceRequest": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SubscriptionPriceResponse": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "dto.SubscriptionRequest": {
             "type": "object",
             "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "default": "monthly",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ]
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "RUB",
+                    "example": "USD"
+                },
                 "end_date": {
                     "type": "string"
                 },
@@ -338,6 +2000,10 @@ const docTemplate = `{
                 "start_date": {
                     "type": "string"
                 },
+                "trial_end_date": {
+                    "type": "string",
+                    "example": "08-2025"
+                },
                 "user_id": {
                     "type": "string"
                 }
@@ -346,6 +2012,12 @@ const docTemplate = `{
         "dto.SubscriptionResponse": {
             "type": "object",
             "properties": {
+                "billing_period": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
                 "end_date": {
                     "type": "string"
                 },
@@ -361,10 +2033,52 @@ const docTemplate = `{
                 "start_date": {
                     "type": "string"
                 },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "cancelled",
+                        "expired"
+                    ]
+                },
+                "trial_end_date": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "string"
                 }
             }
+        },
+        "dto.TotalCostResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "totalCost": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ValidationError": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FieldError"
+                    }
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Токен администратора в формате \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,85 @@
+package billing
+
+import (
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// TotalCost — эталонный расчёт суммарной стоимости подписок за окно window
+// в валюте opts.Currency. Репозиторий считает то же самое на стороне PostgreSQL,
+// результаты сверяются в тестах.
+func TotalCost(subs []models.Subscription, window Period, opts models.CostOptions, rates []models.ExchangeRate) (int, error) {
+	conv := newConverter(opts.Currency, rates)
+
+	totalCost := 0
+	for i := range subs {
+		for _, charge := range Charges(&subs[i], window, opts) {
+			totalCost += conv.convert(charge.Cost, subs[i].Currency, charge.Month)
+		}
+	}
+	if err := conv.err(); err != nil {
+		return 0, err
+	}
+	return totalCost, nil
+}
+
+// MonthlyCosts раскладывает стоимость подписок по месяцам окна window в валюте opts.Currency.
+func MonthlyCosts(subs []models.Subscription, window Period, opts models.CostOptions, rates []models.ExchangeRate) ([]models.MonthlyCost, error) {
+	conv := newConverter(opts.Currency, rates)
+
+	months := Months(window)
+	costs := make([]models.MonthlyCost, len(months))
+	for i, month := range months {
+		costs[i].Month = month
+	}
+
+	for i := range subs {
+		for _, charge := range Charges(&subs[i], window, opts) {
+			gross := conv.convert(charge.Gross, subs[i].Currency, charge.Month)
+			net := conv.convert(charge.Cost, subs[i].Currency, charge.Month)
+
+			cost := &costs[MonthsBetween(window.Start, charge.Month)-1]
+			cost.Gross += gross
+			cost.Discount += gross - net
+			cost.Cost += net
+		}
+	}
+	if err := conv.err(); err != nil {
+		return nil, err
+	}
+	return costs, nil
+}
+
+// Charges возвращает начисления подписки по каждому оплачиваемому месяцу внутри окна
+// в валюте подписки, до и после скидок.
+func Charges(sub *models.Subscription, window Period, opts models.CostOptions) []models.MonthlyCost {
+	clipped, ok := Clip(sub.StartDate, sub.EndDate, window)
+	if !ok {
+		return nil
+	}
+
+	months := Months(clipped)
+	charges := make([]models.MonthlyCost, len(months))
+	for i, month := range months {
+		gross := chargeAt(sub, month, opts)
+		discount := DiscountAt(sub, month, gross)
+		charges[i] = models.MonthlyCost{Month: month, Gross: gross, Discount: discount, Cost: gross - discount}
+	}
+	return charges
+}
+
+// PriceAt возвращает цену подписки, действовавшую в месяце month:
+// последнее изменение цены не позже этого месяца, а без изменений — базовую цену.
+func PriceAt(sub *models.Subscription, month time.Time) int {
+	price := sub.Price
+
+	var effectiveFrom time.Time
+	for _, p := range sub.Prices {
+		if p.EffectiveFrom.After(month) || p.EffectiveFrom.Before(effectiveFrom) {
+			continue
+		}
+		price, effectiveFrom = p.Price, p.EffectiveFrom
+	}
+	return price
+}
@@ -0,0 +1,84 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func TestTotalCost(t *testing.T) {
+	subs := []models.Subscription{
+		{ServiceName: "Netflix", Price: 500, StartDate: month(time.November, 2024), EndDate: monthPtr(time.February, 2025)},
+		{ServiceName: "Yandex Plus", Price: 400, StartDate: month(time.January, 2025)},
+		{ServiceName: "Spotify", Price: 300, StartDate: month(time.January, 2023), EndDate: monthPtr(time.December, 2023)},
+	}
+
+	tests := []struct {
+		name   string
+		window Period
+		want   int
+	}{
+		{"year wrap", Period{Start: month(time.December, 2024), End: month(time.March, 2025)}, 500*3 + 400*3},
+		{"single month", Period{Start: month(time.February, 2025), End: month(time.February, 2025)}, 500 + 400},
+		{"open-ended only", Period{Start: month(time.January, 2026), End: month(time.December, 2026)}, 400 * 12},
+		{"no subscriptions", Period{Start: month(time.January, 2022), End: month(time.December, 2022)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TotalCost(subs, tt.window, models.CostOptions{}, nil)
+			if err != nil {
+				t.Fatalf("TotalCost() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TotalCost() = %d, want %d", got, tt.want)
+			}
+
+			costs, err := MonthlyCosts(subs, tt.window, models.CostOptions{}, nil)
+			if err != nil {
+				t.Fatalf("MonthlyCosts() error: %v", err)
+			}
+			monthlyTotal := 0
+			for _, cost := range costs {
+				monthlyTotal += cost.Cost
+			}
+			if monthlyTotal != tt.want {
+				t.Errorf("sum of MonthlyCosts() = %d, want %d", monthlyTotal, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceHistory(t *testing.T) {
+	sub := models.Subscription{
+		Price:     500,
+		StartDate: month(time.October, 2024),
+		Prices: []models.SubscriptionPrice{
+			{Price: 700, EffectiveFrom: month(time.March, 2025)},
+			{Price: 600, EffectiveFrom: month(time.January, 2025)},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		window Period
+		want   int
+	}{
+		{"before any change", Period{Start: month(time.October, 2024), End: month(time.December, 2024)}, 500 * 3},
+		{"across year and changes", Period{Start: month(time.December, 2024), End: month(time.April, 2025)}, 500 + 600*2 + 700*2},
+		{"after last change", Period{Start: month(time.January, 2026), End: month(time.January, 2026)}, 700},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TotalCost([]models.Subscription{sub}, tt.window, models.CostOptions{}, nil)
+			if err != nil {
+				t.Fatalf("TotalCost() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TotalCost() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
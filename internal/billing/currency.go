@@ -0,0 +1,102 @@
+package billing
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// converter пересчитывает начисления в целевую валюту по курсам месяца начисления
+// и запоминает курсы, которых не хватило.
+type converter struct {
+	target  string
+	rates   map[models.CurrencyMonth]float64
+	missing map[models.CurrencyMonth]struct{}
+}
+
+func newConverter(target string, rates []models.ExchangeRate) *converter {
+	c := &converter{
+		target:  currencyOrDefault(target),
+		rates:   make(map[models.CurrencyMonth]float64, len(rates)),
+		missing: make(map[models.CurrencyMonth]struct{}),
+	}
+	for _, rate := range rates {
+		c.rates[models.CurrencyMonth{Currency: rate.Currency, Month: MonthStart(rate.Month)}] = rate.Rate
+	}
+	return c
+}
+
+func (c *converter) convert(amount int, currency string, month time.Time) int {
+	currency = currencyOrDefault(currency)
+	if amount == 0 || currency == c.target {
+		return amount
+	}
+
+	from, okFrom := c.rate(currency, month)
+	to, okTo := c.rate(c.target, month)
+	if !okFrom || !okTo {
+		return 0
+	}
+	return int(math.Round(float64(amount) * from / to))
+}
+
+func (c *converter) rate(currency string, month time.Time) (float64, bool) {
+	if currency == models.DefaultCurrency {
+		return 1, true
+	}
+
+	key := models.CurrencyMonth{Currency: currency, Month: MonthStart(month)}
+	rate, ok := c.rates[key]
+	if !ok {
+		c.missing[key] = struct{}{}
+	}
+	return rate, ok
+}
+
+func (c *converter) err() error {
+	if len(c.missing) == 0 {
+		return nil
+	}
+
+	missing := make([]models.CurrencyMonth, 0, len(c.missing))
+	for key := range c.missing {
+		missing = append(missing, key)
+	}
+	return models.NewMissingRatesError(missing)
+}
+
+// ForecastRates дополняет курсы rates на месяцы окна window, для которых курса ещё нет,
+// последним известным курсом валюты. Используется для прогноза будущих расходов.
+func ForecastRates(rates []models.ExchangeRate, window Period) []models.ExchangeRate {
+	byCurrency := make(map[string][]models.ExchangeRate)
+	for _, rate := range rates {
+		byCurrency[rate.Currency] = append(byCurrency[rate.Currency], rate)
+	}
+
+	forecast := make([]models.ExchangeRate, 0, len(rates))
+	for currency, known := range byCurrency {
+		sort.Slice(known, func(i, j int) bool { return known[i].Month.Before(known[j].Month) })
+
+		next := 0
+		var latest *models.ExchangeRate
+		for _, month := range Months(window) {
+			for next < len(known) && !known[next].Month.After(month) {
+				latest = &known[next]
+				next++
+			}
+			if latest != nil {
+				forecast = append(forecast, models.ExchangeRate{Currency: currency, Month: month, Rate: latest.Rate})
+			}
+		}
+	}
+	return forecast
+}
+
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return models.DefaultCurrency
+	}
+	return currency
+}
@@ -0,0 +1,106 @@
+package billing
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func TestCurrencyConversion(t *testing.T) {
+	subs := []models.Subscription{
+		{Price: 500, StartDate: month(time.January, 2025), EndDate: monthPtr(time.February, 2025), Currency: "RUB"},
+		{Price: 10, StartDate: month(time.January, 2025), EndDate: monthPtr(time.February, 2025), Currency: "USD"},
+	}
+	rates := []models.ExchangeRate{
+		{Currency: "USD", Month: month(time.January, 2025), Rate: 100},
+		{Currency: "USD", Month: month(time.February, 2025), Rate: 90.5},
+		{Currency: "EUR", Month: month(time.January, 2025), Rate: 105},
+		{Currency: "EUR", Month: month(time.February, 2025), Rate: 100},
+	}
+	window := Period{Start: month(time.January, 2025), End: month(time.February, 2025)}
+
+	tests := []struct {
+		name     string
+		currency string
+		want     []int
+	}{
+		{"default is rubles", "", []int{500 + 1000, 500 + 905}},
+		{"to rubles", "RUB", []int{500 + 1000, 500 + 905}},
+		{"to dollars", "USD", []int{5 + 10, 6 + 10}},
+		{"cross rate", "EUR", []int{5 + 10, 5 + 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			costs, err := MonthlyCosts(subs, window, models.CostOptions{Currency: tt.currency}, rates)
+			if err != nil {
+				t.Fatalf("MonthlyCosts() error: %v", err)
+			}
+			for i, cost := range costs {
+				if cost.Cost != tt.want[i] {
+					t.Errorf("cost for %s = %d, want %d", cost.Month.Format("01-2006"), cost.Cost, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMissingExchangeRates(t *testing.T) {
+	subs := []models.Subscription{
+		{Price: 10, StartDate: month(time.December, 2024), EndDate: monthPtr(time.February, 2025), Currency: "USD"},
+		{Price: 10, StartDate: month(time.February, 2025), EndDate: monthPtr(time.February, 2025), Currency: "EUR"},
+	}
+	rates := []models.ExchangeRate{
+		{Currency: "USD", Month: month(time.January, 2025), Rate: 100},
+	}
+	window := Period{Start: month(time.January, 2024), End: month(time.December, 2025)}
+
+	_, err := TotalCost(subs, window, models.CostOptions{}, rates)
+	if !errors.Is(err, models.ErrExchangeRateMissing) {
+		t.Fatalf("TotalCost() error = %v, want ErrExchangeRateMissing", err)
+	}
+
+	var missingErr *models.MissingRatesError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("TotalCost() error is not *MissingRatesError: %v", err)
+	}
+	want := []models.CurrencyMonth{
+		{Currency: "EUR", Month: month(time.February, 2025)},
+		{Currency: "USD", Month: month(time.December, 2024)},
+		{Currency: "USD", Month: month(time.February, 2025)},
+	}
+	if !reflect.DeepEqual(missingErr.Missing, want) {
+		t.Errorf("missing rates = %v, want %v", missingErr.Missing, want)
+	}
+}
+
+func TestForecastRates(t *testing.T) {
+	rates := []models.ExchangeRate{
+		{Currency: "USD", Month: month(time.March, 2025), Rate: 95},
+		{Currency: "USD", Month: month(time.January, 2025), Rate: 100},
+		{Currency: "EUR", Month: month(time.April, 2025), Rate: 110},
+	}
+	window := Period{Start: month(time.February, 2025), End: month(time.April, 2025)}
+
+	got := ForecastRates(rates, window)
+	sort.Slice(got, func(i, j int) bool {
+		if got[i].Currency != got[j].Currency {
+			return got[i].Currency < got[j].Currency
+		}
+		return got[i].Month.Before(got[j].Month)
+	})
+
+	want := []models.ExchangeRate{
+		{Currency: "EUR", Month: month(time.April, 2025), Rate: 110},
+		{Currency: "USD", Month: month(time.February, 2025), Rate: 100},
+		{Currency: "USD", Month: month(time.March, 2025), Rate: 95},
+		{Currency: "USD", Month: month(time.April, 2025), Rate: 95},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ForecastRates() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package billing
+
+import (
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// chargeAt возвращает начисление по подписке за месяц month с учётом периода оплаты
+// и политики начисления за неполные месяцы.
+func chargeAt(sub *models.Subscription, month time.Time, opts models.CostOptions) int {
+	from, to, ok := activeDays(sub, month)
+	if !ok || Paused(sub, month) {
+		return 0
+	}
+
+	// Дни пробного периода не оплачиваются, но продления по-прежнему отсчитываются от start_date.
+	if trialUntil := TrialUntil(sub); trialUntil != nil && !trialUntil.Before(from) {
+		from = trialUntil.AddDate(0, 0, 1)
+		if from.After(to) {
+			return 0
+		}
+	}
+
+	charge := periodCharge(sub, month, from, to, opts)
+
+	monthly := opts.Normalize || !isPeriodic(sub.BillingPeriod)
+	switch {
+	case opts.Proration == models.ProrationDaily && monthly:
+		return roundDiv(charge*(daysBetween(from, to)+1), daysInMonth(month))
+	case opts.Proration == models.ProrationDaily, opts.Proration == models.ProrationRenewal:
+		// Еженедельные списания и так привязаны к дням продления.
+		if sub.BillingPeriod == models.BillingWeekly && !opts.Normalize {
+			return charge
+		}
+		if renewal := renewalDate(sub, month); renewal.Before(from) || renewal.After(to) {
+			return 0
+		}
+	}
+	return charge
+}
+
+// periodCharge возвращает начисление за месяц month без учёта неполных месяцев.
+func periodCharge(sub *models.Subscription, month, from, to time.Time, opts models.CostOptions) int {
+	price := PriceAt(sub, month)
+	if opts.Normalize {
+		return MonthlyEquivalent(price, sub.BillingPeriod)
+	}
+
+	switch sub.BillingPeriod {
+	case models.BillingWeekly:
+		return price * weeklyRenewals(sub.StartDate, from, to)
+	case models.BillingQuarterly:
+		if (MonthsBetween(sub.StartDate, month)-1)%3 != 0 {
+			return 0
+		}
+	case models.BillingYearly:
+		if (MonthsBetween(sub.StartDate, month)-1)%12 != 0 {
+			return 0
+		}
+	}
+	return price
+}
+
+// MonthlyEquivalent пересчитывает цену за период оплаты в цену за месяц
+// с округлением до целого рубля.
+func MonthlyEquivalent(price int, period models.BillingPeriod) int {
+	switch period {
+	case models.BillingWeekly:
+		return roundDiv(price*52, 12)
+	case models.BillingQuarterly:
+		return roundDiv(price, 3)
+	case models.BillingYearly:
+		return roundDiv(price, 12)
+	default:
+		return price
+	}
+}
+
+// ActiveUntil возвращает последний день действия подписки или nil для бессрочной.
+func ActiveUntil(sub *models.Subscription) *time.Time {
+	return lastDay(sub.EndDate, sub.DatePrecision)
+}
+
+// Paused сообщает, приостановлена ли подписка в месяце month.
+func Paused(sub *models.Subscription, month time.Time) bool {
+	month = MonthStart(month)
+	for _, p := range sub.Pauses {
+		if !month.Before(p.StartMonth) && (p.EndMonth == nil || !month.After(*p.EndMonth)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrialUntil возвращает последний день пробного периода или nil, если его нет.
+func TrialUntil(sub *models.Subscription) *time.Time {
+	return lastDay(sub.TrialEndDate, sub.DatePrecision)
+}
+
+// activeDays возвращает первый и последний день месяца month, в которые действует подписка.
+func activeDays(sub *models.Subscription, month time.Time) (time.Time, time.Time, bool) {
+	from := MonthStart(month)
+	if sub.StartDate.After(from) {
+		from = sub.StartDate
+	}
+
+	to := MonthStart(month).AddDate(0, 1, -1)
+	if until := ActiveUntil(sub); until != nil && until.Before(to) {
+		to = *until
+	}
+	return from, to, !to.Before(from)
+}
+
+// renewalDate возвращает день продления подписки в месяце month. Если в месяце
+// нет такого числа, как в дате начала, продление приходится на последний день месяца.
+func renewalDate(sub *models.Subscription, month time.Time) time.Time {
+	day := min(sub.StartDate.Day(), daysInMonth(month))
+	return MonthStart(month).AddDate(0, 0, day-1)
+}
+
+// weeklyRenewals считает продления еженедельной подписки, попадающие в дни [from; to].
+// Продления идут каждые 7 дней от даты начала подписки.
+func weeklyRenewals(start, from, to time.Time) int {
+	return max(daysBetween(start, to)/7-(daysBetween(start, from)+6)/7+1, 0)
+}
+
+// lastDay переводит дату с точностью до месяца в последний день этого месяца.
+func lastDay(date *time.Time, precision models.DatePrecision) *time.Time {
+	if date == nil || precision == models.PrecisionDay {
+		return date
+	}
+	last := MonthStart(*date).AddDate(0, 1, -1)
+	return &last
+}
+
+func isPeriodic(period models.BillingPeriod) bool {
+	return period == models.BillingWeekly || period == models.BillingQuarterly || period == models.BillingYearly
+}
+
+func daysInMonth(month time.Time) int {
+	return MonthStart(month).AddDate(0, 1, -1).Day()
+}
+
+func daysBetween(from, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func roundDiv(a, b int) int {
+	return (2*a + b) / (2 * b)
+}
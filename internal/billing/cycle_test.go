@@ -0,0 +1,253 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func TestBillingPeriodCharges(t *testing.T) {
+	tests := []struct {
+		name   string
+		sub    models.Subscription
+		window Period
+		opts   models.CostOptions
+		want   []int
+	}{
+		{
+			name:   "monthly",
+			sub:    models.Subscription{Price: 300, StartDate: month(time.November, 2024), BillingPeriod: models.BillingMonthly},
+			window: Period{Start: month(time.December, 2024), End: month(time.February, 2025)},
+			want:   []int{300, 300, 300},
+		},
+		{
+			name:   "quarterly charged every third month across year",
+			sub:    models.Subscription{Price: 900, StartDate: month(time.November, 2024), BillingPeriod: models.BillingQuarterly},
+			window: Period{Start: month(time.November, 2024), End: month(time.June, 2025)},
+			want:   []int{900, 0, 0, 900, 0, 0, 900, 0},
+		},
+		{
+			name:   "yearly charged on anniversary month",
+			sub:    models.Subscription{Price: 2400, StartDate: month(time.March, 2024), BillingPeriod: models.BillingYearly},
+			window: Period{Start: month(time.January, 2025), End: month(time.April, 2025)},
+			want:   []int{0, 0, 2400, 0},
+		},
+		{
+			name:   "yearly ended before anniversary",
+			sub:    models.Subscription{Price: 2400, StartDate: month(time.March, 2024), EndDate: monthPtr(time.February, 2025), BillingPeriod: models.BillingYearly},
+			window: Period{Start: month(time.January, 2025), End: month(time.April, 2025)},
+			want:   []int{0, 0},
+		},
+		{
+			name:   "weekly counts renewals in each month",
+			sub:    models.Subscription{Price: 100, StartDate: month(time.January, 2025), BillingPeriod: models.BillingWeekly},
+			window: Period{Start: month(time.January, 2025), End: month(time.March, 2025)},
+			want:   []int{500, 400, 400},
+		},
+		{
+			name:   "normalized",
+			sub:    models.Subscription{Price: 1000, StartDate: month(time.January, 2025), BillingPeriod: models.BillingQuarterly},
+			window: Period{Start: month(time.January, 2025), End: month(time.February, 2025)},
+			opts:   models.CostOptions{Normalize: true},
+			want:   []int{333, 333},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges := Charges(&tt.sub, tt.window, tt.opts)
+			if len(charges) != len(tt.want) {
+				t.Fatalf("Charges() returned %d months, want %d", len(charges), len(tt.want))
+			}
+			for i, charge := range charges {
+				if charge.Cost != tt.want[i] {
+					t.Errorf("charge for %s = %d, want %d", charge.Month.Format("01-2006"), charge.Cost, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMonthlyEquivalent(t *testing.T) {
+	tests := []struct {
+		price  int
+		period models.BillingPeriod
+		want   int
+	}{
+		{500, models.BillingMonthly, 500},
+		{500, "", 500},
+		{1000, models.BillingQuarterly, 333},
+		{1001, models.BillingQuarterly, 334},
+		{2990, models.BillingYearly, 249},
+		{100, models.BillingWeekly, 433},
+	}
+
+	for _, tt := range tests {
+		if got := MonthlyEquivalent(tt.price, tt.period); got != tt.want {
+			t.Errorf("MonthlyEquivalent(%d, %q) = %d, want %d", tt.price, tt.period, got, tt.want)
+		}
+	}
+}
+
+func day(d int, m time.Month, year int) time.Time {
+	return time.Date(year, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func dayPtr(d int, m time.Month, year int) *time.Time {
+	t := day(d, m, year)
+	return &t
+}
+
+func TestProration(t *testing.T) {
+	sub := models.Subscription{
+		Price:         310,
+		StartDate:     day(25, time.January, 2025),
+		EndDate:       dayPtr(20, time.March, 2025),
+		DatePrecision: models.PrecisionDay,
+	}
+	window := Period{Start: month(time.January, 2025), End: month(time.March, 2025)}
+
+	tests := []struct {
+		name      string
+		sub       models.Subscription
+		proration models.Proration
+		want      []int
+	}{
+		{"full", sub, models.ProrationFull, []int{310, 310, 310}},
+		{"daily", sub, models.ProrationDaily, []int{70, 310, 200}},
+		{"renewal before end", sub, models.ProrationRenewal, []int{310, 310, 0}},
+		{
+			name: "renewal clamped to short month",
+			sub: models.Subscription{
+				Price: 310, StartDate: day(31, time.January, 2025), EndDate: dayPtr(28, time.February, 2025),
+				DatePrecision: models.PrecisionDay,
+			},
+			proration: models.ProrationRenewal,
+			want:      []int{310, 310},
+		},
+		{
+			name: "month precision is never prorated",
+			sub: models.Subscription{
+				Price: 310, StartDate: month(time.January, 2025), EndDate: monthPtr(time.March, 2025),
+				DatePrecision: models.PrecisionMonth,
+			},
+			proration: models.ProrationDaily,
+			want:      []int{310, 310, 310},
+		},
+		{
+			name: "quarterly charged only on renewal day",
+			sub: models.Subscription{
+				Price: 900, StartDate: day(10, time.January, 2025), EndDate: dayPtr(5, time.April, 2025),
+				BillingPeriod: models.BillingQuarterly, DatePrecision: models.PrecisionDay,
+			},
+			proration: models.ProrationDaily,
+			want:      []int{900, 0, 0},
+		},
+		{
+			name: "weekly counts renewals up to end day",
+			sub: models.Subscription{
+				Price: 100, StartDate: day(25, time.January, 2025), EndDate: dayPtr(10, time.February, 2025),
+				BillingPeriod: models.BillingWeekly, DatePrecision: models.PrecisionDay,
+			},
+			proration: models.ProrationFull,
+			want:      []int{100, 200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges := Charges(&tt.sub, window, models.CostOptions{Proration: tt.proration})
+			if len(charges) != len(tt.want) {
+				t.Fatalf("Charges() returned %d months, want %d", len(charges), len(tt.want))
+			}
+			for i, charge := range charges {
+				if charge.Cost != tt.want[i] {
+					t.Errorf("charge for %s = %d, want %d", charge.Month.Format("01-2006"), charge.Cost, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTrialPeriod(t *testing.T) {
+	monthly := models.Subscription{
+		Price: 310, StartDate: day(10, time.January, 2025), TrialEndDate: dayPtr(9, time.February, 2025),
+		BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionDay,
+	}
+	window := Period{Start: month(time.January, 2025), End: month(time.March, 2025)}
+
+	tests := []struct {
+		name      string
+		sub       models.Subscription
+		proration models.Proration
+		want      []int
+	}{
+		{
+			name: "month precision trial months are free",
+			sub: models.Subscription{
+				Price: 300, StartDate: month(time.December, 2024), TrialEndDate: monthPtr(time.January, 2025),
+				BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth,
+			},
+			proration: models.ProrationFull,
+			want:      []int{0, 300, 300},
+		},
+		{"full month after trial", monthly, models.ProrationFull, []int{0, 310, 310}},
+		{"daily charges days after trial", monthly, models.ProrationDaily, []int{0, 210, 310}},
+		{"renewal on the day after trial", monthly, models.ProrationRenewal, []int{0, 310, 310}},
+		{
+			name: "weekly renewals during trial are free",
+			sub: models.Subscription{
+				Price: 100, StartDate: day(1, time.January, 2025), TrialEndDate: dayPtr(14, time.January, 2025),
+				BillingPeriod: models.BillingWeekly, DatePrecision: models.PrecisionDay,
+			},
+			proration: models.ProrationFull,
+			want:      []int{300, 400, 400},
+		},
+		{
+			name: "quarterly charge inside trial is free",
+			sub: models.Subscription{
+				Price: 900, StartDate: month(time.January, 2025), TrialEndDate: monthPtr(time.January, 2025),
+				BillingPeriod: models.BillingQuarterly, DatePrecision: models.PrecisionMonth,
+			},
+			proration: models.ProrationFull,
+			want:      []int{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			charges := Charges(&tt.sub, window, models.CostOptions{Proration: tt.proration})
+			if len(charges) != len(tt.want) {
+				t.Fatalf("Charges() returned %d months, want %d", len(charges), len(tt.want))
+			}
+			for i, charge := range charges {
+				if charge.Cost != tt.want[i] {
+					t.Errorf("charge for %s = %d, want %d", charge.Month.Format("01-2006"), charge.Cost, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPausedMonths(t *testing.T) {
+	sub := models.Subscription{
+		Price: 300, StartDate: month(time.January, 2025), BillingPeriod: models.BillingMonthly,
+		Pauses: []models.SubscriptionPause{
+			{StartMonth: month(time.February, 2025), EndMonth: monthPtr(time.March, 2025)},
+			{StartMonth: month(time.May, 2025)},
+		},
+	}
+	window := Period{Start: month(time.January, 2025), End: month(time.June, 2025)}
+
+	want := []int{300, 0, 0, 300, 0, 0}
+	charges := Charges(&sub, window, models.CostOptions{})
+	if len(charges) != len(want) {
+		t.Fatalf("Charges() returned %d months, want %d", len(charges), len(want))
+	}
+	for i, charge := range charges {
+		if charge.Cost != want[i] {
+			t.Errorf("charge for %s = %d, want %d", charge.Month.Format("01-2006"), charge.Cost, want[i])
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package billing
+
+import (
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// DiscountAt возвращает сумму скидок подписки на начисление charge за месяц month.
+// Процентные скидки округляются до целого, сумма скидок не превышает начисление.
+func DiscountAt(sub *models.Subscription, month time.Time, charge int) int {
+	month = MonthStart(month)
+
+	discount := 0
+	for _, d := range sub.Discounts {
+		if month.Before(d.StartMonth) || (d.EndMonth != nil && month.After(*d.EndMonth)) {
+			continue
+		}
+
+		switch d.Kind {
+		case models.DiscountPercent:
+			discount += roundDiv(charge*d.Value, 100)
+		case models.DiscountFixed:
+			discount += d.Value
+		}
+	}
+	return min(discount, charge)
+}
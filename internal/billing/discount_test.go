@@ -0,0 +1,78 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func TestDiscounts(t *testing.T) {
+	window := Period{Start: month(time.January, 2025), End: month(time.April, 2025)}
+
+	tests := []struct {
+		name      string
+		discounts []models.SubscriptionDiscount
+		want      []models.MonthlyCost
+	}{
+		{
+			name: "percent for first months",
+			discounts: []models.SubscriptionDiscount{
+				{Kind: models.DiscountPercent, Value: 50, StartMonth: month(time.January, 2025), EndMonth: monthPtr(time.February, 2025)},
+			},
+			want: []models.MonthlyCost{
+				{Gross: 399, Discount: 200, Cost: 199},
+				{Gross: 399, Discount: 200, Cost: 199},
+				{Gross: 399, Discount: 0, Cost: 399},
+				{Gross: 399, Discount: 0, Cost: 399},
+			},
+		},
+		{
+			name: "open-ended fixed and percent are summed",
+			discounts: []models.SubscriptionDiscount{
+				{Kind: models.DiscountFixed, Value: 100, StartMonth: month(time.March, 2025)},
+				{Kind: models.DiscountPercent, Value: 10, StartMonth: month(time.April, 2025), EndMonth: monthPtr(time.April, 2025)},
+			},
+			want: []models.MonthlyCost{
+				{Gross: 399, Discount: 0, Cost: 399},
+				{Gross: 399, Discount: 0, Cost: 399},
+				{Gross: 399, Discount: 100, Cost: 299},
+				{Gross: 399, Discount: 140, Cost: 259},
+			},
+		},
+		{
+			name: "discount never exceeds charge",
+			discounts: []models.SubscriptionDiscount{
+				{Kind: models.DiscountFixed, Value: 500, StartMonth: month(time.January, 2025), EndMonth: monthPtr(time.January, 2025)},
+				{Kind: models.DiscountPercent, Value: 100, StartMonth: month(time.February, 2025), EndMonth: monthPtr(time.February, 2025)},
+			},
+			want: []models.MonthlyCost{
+				{Gross: 399, Discount: 399, Cost: 0},
+				{Gross: 399, Discount: 399, Cost: 0},
+				{Gross: 399, Discount: 0, Cost: 399},
+				{Gross: 399, Discount: 0, Cost: 399},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := models.Subscription{
+				Price: 399, StartDate: month(time.January, 2025),
+				BillingPeriod: models.BillingMonthly, Discounts: tt.discounts,
+			}
+
+			charges := Charges(&sub, window, models.CostOptions{})
+			if len(charges) != len(tt.want) {
+				t.Fatalf("Charges() returned %d months, want %d", len(charges), len(tt.want))
+			}
+			for i, charge := range charges {
+				want := tt.want[i]
+				if charge.Gross != want.Gross || charge.Discount != want.Discount || charge.Cost != want.Cost {
+					t.Errorf("charge for %s = %d/%d/%d, want %d/%d/%d", charge.Month.Format("01-2006"),
+						charge.Gross, charge.Discount, charge.Cost, want.Gross, want.Discount, want.Cost)
+				}
+			}
+		})
+	}
+}
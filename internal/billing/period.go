@@ -0,0 +1,61 @@
+package billing
+
+import "time"
+
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// MonthsBetween возвращает количество месяцев в интервале [start; end] включительно.
+func MonthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month()) + 1
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+// NextMonths возвращает окно из months месяцев, начиная со следующего после now.
+func NextMonths(now time.Time, months int) Period {
+	start := MonthStart(now).AddDate(0, 1, 0)
+	return Period{Start: start, End: start.AddDate(0, months-1, 0)}
+}
+
+// Clip обрезает период подписки [start; end] по окну window.
+// Бессрочная подписка (end == nil) считается действующей до конца окна.
+func Clip(start time.Time, end *time.Time, window Period) (Period, bool) {
+	clipped := Period{Start: MonthStart(start), End: MonthStart(window.End)}
+	if end != nil && end.Before(window.End) {
+		clipped.End = MonthStart(*end)
+	}
+	if clipped.Start.Before(window.Start) {
+		clipped.Start = MonthStart(window.Start)
+	}
+
+	if clipped.End.Before(clipped.Start) {
+		return Period{}, false
+	}
+	return clipped, true
+}
+
+func BillableMonths(start time.Time, end *time.Time, window Period) int {
+	clipped, ok := Clip(start, end, window)
+	if !ok {
+		return 0
+	}
+	return MonthsBetween(clipped.Start, clipped.End)
+}
+
+// Months возвращает начала всех месяцев периода по порядку.
+func Months(p Period) []time.Time {
+	months := make([]time.Time, MonthsBetween(p.Start, p.End))
+	for i := range months {
+		months[i] = MonthStart(p.Start).AddDate(0, i, 0)
+	}
+	return months
+}
@@ -0,0 +1,162 @@
+package billing
+
+import (
+	"testing"
+	"time"
+)
+
+func month(m time.Month, year int) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthPtr(m time.Month, year int) *time.Time {
+	t := month(m, year)
+	return &t
+}
+
+func TestMonthsBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  int
+	}{
+		{"same month", month(time.July, 2025), month(time.July, 2025), 1},
+		{"within year", month(time.July, 2025), month(time.September, 2025), 3},
+		{"year wrap", month(time.November, 2024), month(time.February, 2025), 4},
+		{"several years", month(time.March, 2022), month(time.March, 2025), 37},
+		{"end before start", month(time.February, 2025), month(time.November, 2024), 0},
+		{"ignores day of month", time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC), time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC), 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthsBetween(tt.start, tt.end); got != tt.want {
+				t.Errorf("MonthsBetween(%s, %s) = %d, want %d", tt.start.Format("01-2006"), tt.end.Format("01-2006"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBillableMonths(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  time.Time
+		end    *time.Time
+		window Period
+		want   int
+	}{
+		{
+			name:   "inside window",
+			start:  month(time.August, 2025),
+			end:    monthPtr(time.September, 2025),
+			window: Period{Start: month(time.July, 2025), End: month(time.September, 2025)},
+			want:   2,
+		},
+		{
+			name:   "year wrap inside window",
+			start:  month(time.November, 2024),
+			end:    monthPtr(time.February, 2025),
+			window: Period{Start: month(time.January, 2024), End: month(time.December, 2025)},
+			want:   4,
+		},
+		{
+			name:   "window crosses year boundary",
+			start:  month(time.January, 2024),
+			end:    monthPtr(time.December, 2026),
+			window: Period{Start: month(time.October, 2024), End: month(time.March, 2025)},
+			want:   6,
+		},
+		{
+			name:   "clipped at window start",
+			start:  month(time.June, 2023),
+			end:    monthPtr(time.February, 2025),
+			window: Period{Start: month(time.December, 2024), End: month(time.June, 2025)},
+			want:   3,
+		},
+		{
+			name:   "open-ended runs to window end",
+			start:  month(time.October, 2024),
+			end:    nil,
+			window: Period{Start: month(time.January, 2024), End: month(time.March, 2025)},
+			want:   6,
+		},
+		{
+			name:   "open-ended started before window",
+			start:  month(time.January, 2020),
+			end:    nil,
+			window: Period{Start: month(time.January, 2025), End: month(time.December, 2025)},
+			want:   12,
+		},
+		{
+			name:   "single-month window",
+			start:  month(time.January, 2024),
+			end:    nil,
+			window: Period{Start: month(time.May, 2025), End: month(time.May, 2025)},
+			want:   1,
+		},
+		{
+			name:   "single-month window at subscription end",
+			start:  month(time.January, 2025),
+			end:    monthPtr(time.May, 2025),
+			window: Period{Start: month(time.May, 2025), End: month(time.May, 2025)},
+			want:   1,
+		},
+		{
+			name:   "ended before window",
+			start:  month(time.January, 2024),
+			end:    monthPtr(time.December, 2024),
+			window: Period{Start: month(time.January, 2025), End: month(time.March, 2025)},
+			want:   0,
+		},
+		{
+			name:   "starts after window",
+			start:  month(time.April, 2025),
+			end:    nil,
+			window: Period{Start: month(time.January, 2025), End: month(time.March, 2025)},
+			want:   0,
+		},
+		{
+			name:   "inverted window",
+			start:  month(time.January, 2025),
+			end:    nil,
+			window: Period{Start: month(time.June, 2025), End: month(time.March, 2025)},
+			want:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BillableMonths(tt.start, tt.end, tt.window); got != tt.want {
+				t.Errorf("BillableMonths() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonths(t *testing.T) {
+	got := Months(Period{Start: month(time.November, 2024), End: month(time.February, 2025)})
+	want := []time.Time{
+		month(time.November, 2024),
+		month(time.December, 2024),
+		month(time.January, 2025),
+		month(time.February, 2025),
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Months() returned %d months, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Months()[%d] = %s, want %s", i, got[i].Format("01-2006"), want[i].Format("01-2006"))
+		}
+	}
+}
+
+func TestNextMonths(t *testing.T) {
+	got := NextMonths(time.Date(2025, time.November, 17, 15, 4, 5, 0, time.UTC), 3)
+	want := Period{Start: month(time.December, 2025), End: month(time.February, 2026)}
+	if got != want {
+		t.Errorf("NextMonths() = %+v, want %+v", got, want)
+	}
+}
@@ -1,9 +1,15 @@
 package config
 
 import (
+	"fmt"
+	"log/slog"
 	"os"
+	"time"
 )
 
+// defaultIdempotencyTTL — сколько хранится ответ на запрос с Idempotency-Key, если IDEMPOTENCY_TTL не задан.
+const defaultIdempotencyTTL = 24 * time.Hour
+
 type Config struct {
 	DBHost     string
 	DBPort     string
@@ -11,15 +17,90 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBSSL      string
+	// AdminToken — токен доступа к административным ручкам изменения курсов валют.
+	AdminToken string
+
+	IdempotencyTTL time.Duration
+
+	LogLevel  slog.Level
+	LogFormat string
+
+	// InvalidEnv — переменные окружения с неверными значениями, заменёнными
+	// на значения по умолчанию. Load вызывается до настройки логгера, поэтому
+	// о них пишет в лог вызывающий код.
+	InvalidEnv []InvalidEnv
+}
+
+// InvalidEnv — неверное значение переменной окружения и использованное вместо него значение.
+type InvalidEnv struct {
+	Key     string
+	Value   string
+	Default string
 }
 
 func Load() *Config {
-	return &Config{
+	cfg := &Config{
 		DBHost:     os.Getenv("DB_HOST"),
 		DBPort:     os.Getenv("DB_PORT"),
 		DBUser:     os.Getenv("DB_USER"),
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBName:     os.Getenv("DB_NAME"),
 		DBSSL:      os.Getenv("DB_SSL"),
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+	}
+	cfg.IdempotencyTTL = cfg.durationEnv("IDEMPOTENCY_TTL", defaultIdempotencyTTL)
+	cfg.LogLevel = cfg.levelEnv("LOG_LEVEL", slog.LevelInfo)
+	cfg.LogFormat = cfg.oneOfEnv("LOG_FORMAT", "json", "text")
+	return cfg
+}
+
+func (c *Config) invalid(key, value string, defaultValue any) {
+	c.InvalidEnv = append(c.InvalidEnv, InvalidEnv{Key: key, Value: value, Default: fmt.Sprint(defaultValue)})
+}
+
+// durationEnv читает длительность в формате time.ParseDuration (например, 24h).
+// Пустое или неверное значение заменяется на defaultValue.
+func (c *Config) durationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		c.invalid(key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// levelEnv читает уровень логирования: debug, info, warn или error.
+// Пустое или неверное значение заменяется на defaultValue.
+func (c *Config) levelEnv(key string, defaultValue slog.Level) slog.Level {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		c.invalid(key, value, defaultValue)
+		return defaultValue
+	}
+	return level
+}
+
+// oneOfEnv читает значение из списка allowed. Пустое или неизвестное значение
+// заменяется на первый элемент списка.
+func (c *Config) oneOfEnv(key string, allowed ...string) string {
+	value := os.Getenv(key)
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+	if value != "" {
+		c.invalid(key, value, allowed[0])
 	}
+	return allowed[0]
 }
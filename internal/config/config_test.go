@@ -0,0 +1,27 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadInvalidEnv(t *testing.T) {
+	t.Setenv("IDEMPOTENCY_TTL", "сутки")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "xml")
+
+	cfg := Load()
+
+	if cfg.IdempotencyTTL != defaultIdempotencyTTL || cfg.LogLevel != slog.LevelDebug || cfg.LogFormat != "json" {
+		t.Errorf("ttl = %v, level = %v, format = %q", cfg.IdempotencyTTL, cfg.LogLevel, cfg.LogFormat)
+	}
+	want := []InvalidEnv{
+		{Key: "IDEMPOTENCY_TTL", Value: "сутки", Default: (24 * time.Hour).String()},
+		{Key: "LOG_FORMAT", Value: "xml", Default: "json"},
+	}
+	if !reflect.DeepEqual(cfg.InvalidEnv, want) {
+		t.Errorf("InvalidEnv = %+v, want %+v", cfg.InvalidEnv, want)
+	}
+}
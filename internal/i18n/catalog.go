@@ -0,0 +1,122 @@
+package i18n
+
+// catalog — переводы сообщений API. Ключ — английский текст из кода, он же
+// ответ для English. Новое сообщение для клиента нужно добавить и сюда.
+var catalog = map[Lang]map[string]string{
+	Russian: {
+		// Статусы HTTP — заголовки ошибок без доменного типа.
+		"Bad Request":            "Некорректный запрос",
+		"Unauthorized":           "Требуется авторизация",
+		"Not Found":              "Не найдено",
+		"Conflict":               "Конфликт",
+		"Precondition Failed":    "Предусловие не выполнено",
+		"Unsupported Media Type": "Неподдерживаемый тип содержимого",
+		"Unprocessable Entity":   "Необрабатываемые данные",
+		"Internal Server Error":  "Внутренняя ошибка сервера",
+
+		// Заголовки доменных ошибок.
+		"Subscription not found":                         "Подписка не найдена",
+		"Subscription pause not found":                   "Пауза подписки не найдена",
+		"Exchange rate not found":                        "Курс валюты не найден",
+		"Period ends before it starts":                   "Период заканчивается раньше, чем начинается",
+		"Invalid field value":                            "Недопустимое значение поля",
+		"Price change is outside of subscription period": "Изменение цены вне периода подписки",
+		"Discount is outside of subscription period":     "Скидка вне периода подписки",
+		"Pause is outside of subscription period":        "Пауза вне периода подписки",
+		"Missing exchange rates":                         "Нет курсов валют для пересчёта",
+		"Invalid subscription status transition":         "Недопустимая смена статуса подписки",
+		"Pause overlaps with another subscription pause": "Пауза пересекается с другой паузой подписки",
+		"Subscription pause is in progress":              "Пауза подписки ещё не закончилась",
+		"Subscription already exists":                    "Такая подписка уже существует",
+		"Conflicting concurrent change":                  "Конфликт с параллельным изменением",
+		"Subscription version does not match":            "Версия подписки не совпадает",
+		"Invalid merge patch":                            "Некорректный merge patch",
+		"Validation failed":                              "Ошибка валидации",
+
+		// Подробности доменных ошибок.
+		"subscription with this id does not exist":                                 "подписки с таким id нет",
+		"subscription has no pause with this id":                                   "у подписки нет паузы с таким id",
+		"no exchange rate is set for this currency and month":                      "курс этой валюты на этот месяц не задан",
+		"end of the period must not be before its start":                           "конец периода не может быть раньше его начала",
+		"field value is not allowed":                                               "значение поля недопустимо",
+		"effective_from is outside of subscription period":                         "effective_from вне периода подписки",
+		"discount months are outside of subscription period":                       "месяцы скидки вне периода подписки",
+		"start_month is outside of subscription period":                            "start_month вне периода подписки",
+		"exchange rates listed in missing_rates are required for conversion":       "для пересчёта нужны курсы, перечисленные в missing_rates",
+		"subscription cannot move to this status from its current status":          "подписку нельзя перевести в этот статус из текущего",
+		"pause overlaps with another subscription pause":                           "пауза пересекается с другой паузой подписки",
+		"pause is in progress, resume subscription instead":                        "пауза ещё идёт, возобновите подписку",
+		"user already has a subscription to this service with the same start_date": "у пользователя уже есть подписка на этот сервис с той же start_date",
+		"data was changed by a concurrent request, retry the request":              "данные изменены параллельным запросом, повторите запрос",
+		"subscription version does not match If-Match":                             "версия подписки не совпадает с If-Match",
+		"merge patch cannot be applied to the subscription":                        "merge patch нельзя применить к подписке",
+
+		// Подробности ошибок.
+		"missing or invalid admin token":                            "токен администратора не передан или неверен",
+		"period must not be longer than 120 months":                 "период не может быть длиннее 120 месяцев",
+		"invalid request":                                           "некорректный запрос",
+		"invalid request body parameter":                            "некорректный параметр тела запроса",
+		"invalid query parameter":                                   "некорректный параметр запроса",
+		"invalid envelope parameter":                                "некорректный параметр envelope",
+		"invalid group_by query parameter":                          "некорректный параметр group_by",
+		"invalid If-Match header":                                   "некорректный заголовок If-Match",
+		"invalid batch mode":                                        "некорректный режим пакета",
+		"invalid currency path parameter":                           "некорректная валюта в пути запроса",
+		"invalid month path parameter":                              "некорректный месяц в пути запроса",
+		"invalid exchange rate parameter":                           "некорректный параметр курса валюты",
+		"invalid exchange rates query parameter":                    "некорректный параметр запроса курсов валют",
+		"invalid subscription period in path parameter":             "некорректный период подписки в пути запроса",
+		"missing or invalid subscription id path parameter value":   "id подписки в пути запроса не передан или некорректен",
+		"missing or invalid pause id path parameter value":          "id паузы в пути запроса не передан или некорректен",
+		"content type must be application/merge-patch+json":         "тип содержимого должен быть application/merge-patch+json",
+		"batch must contain from 1 to 1000 operations":              "пакет должен содержать от 1 до 1000 операций",
+		"Idempotency-Key is too long":                               "слишком длинный Idempotency-Key",
+		"Idempotency-Key was already used with a different request": "Idempotency-Key уже использован с другим запросом",
+		"request with this Idempotency-Key is still in progress":    "запрос с этим Idempotency-Key ещё выполняется",
+		"failed to check idempotency key":                           "не удалось проверить ключ идемпотентности",
+		"failed to create subscription":                             "не удалось создать подписку",
+		"failed to get subscription":                                "не удалось получить подписку",
+		"failed to get all subscriptions":                           "не удалось получить подписки",
+		"failed to get subscriptions page":                          "не удалось получить страницу подписок",
+		"failed to count subscriptions":                             "не удалось посчитать подписки",
+		"failed to update subscription":                             "не удалось обновить подписку",
+		"failed to patch subscription":                              "не удалось частично обновить подписку",
+		"failed to delete subscription":                             "не удалось удалить подписку",
+		"failed to change subscription status":                      "не удалось сменить статус подписки",
+		"failed to apply batch":                                     "не удалось выполнить пакет",
+		"failed to add subscription price":                          "не удалось изменить цену подписки",
+		"failed to get subscription prices":                         "не удалось получить историю цен",
+		"failed to add subscription discount":                       "не удалось добавить скидку",
+		"failed to get subscription discounts":                      "не удалось получить скидки",
+		"failed to add subscription pause":                          "не удалось добавить паузу",
+		"failed to get subscription pauses":                         "не удалось получить паузы",
+		"failed to delete subscription pause":                       "не удалось удалить паузу",
+		"failed to save exchange rate":                              "не удалось сохранить курс валюты",
+		"failed to get exchange rates":                              "не удалось получить курсы валют",
+		"failed to delete exchange rate":                            "не удалось удалить курс валюты",
+		"failed to get subscriptions cost for period":               "не удалось посчитать стоимость подписок за период",
+		"failed to get grouped subscriptions cost for period":       "не удалось посчитать стоимость подписок по группам",
+		"failed to get monthly subscriptions cost for period":       "не удалось посчитать стоимость подписок по месяцам",
+		"failed to forecast subscriptions cost":                     "не удалось спрогнозировать стоимость подписок",
+
+		// Результаты операций пакета.
+		"invalid operation": "некорректная операция",
+		"rolled back":       "отменена",
+
+		// Нарушения валидации: первый аргумент — имя поля.
+		"%s is required":                            "поле %s обязательно",
+		"%s is required for %s":                     "поле %s обязательно для %s",
+		"%s is required and cannot be null":         "поле %s обязательно и не может быть null",
+		"%s must not be negative":                   "поле %s не может быть отрицательным",
+		"%s must be a UUID":                         "поле %s должно быть UUID",
+		"%s must be MM-YYYY or YYYY-MM-DD":          "поле %s должно быть в формате MM-YYYY или YYYY-MM-DD",
+		"%s must use the same format as start_date": "поле %s должно быть в том же формате, что и start_date",
+		"%s must not be before start_date":          "поле %s не может быть раньше start_date",
+		"%s must not be after end_date":             "поле %s не может быть позже end_date",
+		"%s must be one of %s":                      "поле %s должно принимать одно из значений: %s",
+		"%s must be an ISO 4217 code like USD":      "поле %s должно быть кодом ISO 4217, например USD",
+		"%s must be %s":                             "поле %s должно иметь тип %s",
+		"%s must not be set for %s":                 "поле %s не задаётся для %s",
+		"%s cannot be changed":                      "поле %s нельзя изменить",
+	},
+}
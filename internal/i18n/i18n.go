@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang — язык сообщений API.
+type Lang string
+
+const (
+	English Lang = "en"
+	Russian Lang = "ru"
+
+	// Default используется, если клиент не передал Accept-Language
+	// или не поддерживает ни один из наших языков.
+	Default = English
+)
+
+// Text переводит сообщение msg на язык lang. Сообщения в коде пишутся
+// по-английски и служат ключами каталога; без перевода msg возвращается как есть.
+func Text(lang Lang, msg string) string {
+	if translated, ok := catalog[lang][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf переводит шаблон format на язык lang и подставляет в него args.
+func Sprintf(lang Lang, format string, args ...any) string {
+	return fmt.Sprintf(Text(lang, format), args...)
+}
+
+// FromRequest выбирает язык ответа по заголовку Accept-Language запроса.
+func FromRequest(r *http.Request) Lang {
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// Negotiate выбирает из Accept-Language (RFC 9110) поддерживаемый язык
+// с наибольшим весом q. Региональные варианты (ru-RU, en-GB) сводятся к основному языку.
+func Negotiate(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		switch lang := Lang(primary); {
+		case primary == "*":
+			candidates = append(candidates, candidate{Default, q})
+		case catalog[lang] != nil || lang == English:
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	if len(candidates) == 0 {
+		return Default
+	}
+	return candidates[0].lang
+}
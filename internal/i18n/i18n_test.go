@@ -0,0 +1,153 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Lang
+	}{
+		{"", Default},
+		{"ru", Russian},
+		{"ru-RU,ru;q=0.9,en-US;q=0.8", Russian},
+		{"de-DE, en;q=0.5, ru;q=0.7", Russian},
+		{"en-GB", English},
+		{"ru;q=0, en", English},
+		{"de, fr", Default},
+		{"*", Default},
+		{"ru;q=bad, en;q=0.1", English},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	if got := Sprintf(Russian, "%s is required for %s", "id", "update"); got != "поле id обязательно для update" {
+		t.Errorf("Sprintf(ru) = %q", got)
+	}
+	if got := Sprintf(English, "%s is required for %s", "id", "update"); got != "id is required for update" {
+		t.Errorf("Sprintf(en) = %q", got)
+	}
+	if got := Text(Russian, "no such message"); got != "no such message" {
+		t.Errorf("Text() without translation = %q, want the message itself", got)
+	}
+}
+
+// statusCodes — статусы, с которыми вызывается problem.Write: их текст
+// становится заголовком ошибки и тоже переводится.
+var statusCodes = map[string]int{
+	"StatusBadRequest":           http.StatusBadRequest,
+	"StatusUnauthorized":         http.StatusUnauthorized,
+	"StatusNotFound":             http.StatusNotFound,
+	"StatusConflict":             http.StatusConflict,
+	"StatusPreconditionFailed":   http.StatusPreconditionFailed,
+	"StatusUnsupportedMediaType": http.StatusUnsupportedMediaType,
+	"StatusUnprocessableEntity":  http.StatusUnprocessableEntity,
+	"StatusInternalServerError":  http.StatusInternalServerError,
+}
+
+// TestRussianCatalogCoversMessages ищет в исходниках сервиса сообщения для
+// клиента — detail в problem.Write, запасной detail в problem.WriteError,
+// шаблоны нарушений валидации и аргументы Text/Sprintf — и проверяет, что
+// у каждого есть русский перевод.
+func TestRussianCatalogCoversMessages(t *testing.T) {
+	messages := map[string]token.Position{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			pkg, name := callee(call)
+			for _, arg := range messageArgs(file.Name.Name, pkg, name, call.Args) {
+				if msg, ok := stringLit(arg); ok {
+					messages[msg] = fset.Position(arg.Pos())
+				}
+				if sel, ok := arg.(*ast.SelectorExpr); ok && name == "Write" {
+					code, known := statusCodes[sel.Sel.Name]
+					if !known {
+						t.Errorf("%s: статус %s не указан в statusCodes", fset.Position(arg.Pos()), sel.Sel.Name)
+						continue
+					}
+					messages[http.StatusText(code)] = fset.Position(arg.Pos())
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ошибка чтения исходников: %v", err)
+	}
+
+	if len(messages) == 0 {
+		t.Fatal("в исходниках не найдено ни одного сообщения")
+	}
+	for msg, pos := range messages {
+		if _, ok := catalog[Russian][msg]; !ok {
+			t.Errorf("%s: нет русского перевода для %q", pos, msg)
+		}
+	}
+}
+
+// callee возвращает пакет (или получатель) и имя вызываемой функции.
+func callee(call *ast.CallExpr) (string, string) {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return "", fun.Name
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok {
+			return x.Name, fun.Sel.Name
+		}
+		return "", fun.Sel.Name
+	}
+	return "", ""
+}
+
+// messageArgs возвращает аргументы вызова, в которых передаётся сообщение для клиента.
+func messageArgs(filePkg, pkg, name string, args []ast.Expr) []ast.Expr {
+	inProblem := pkg == "problem" || (pkg == "" && filePkg == "problem")
+	switch {
+	case inProblem && name == "Write" && len(args) == 4:
+		return args[2:4]
+	case inProblem && name == "WriteError" && len(args) == 4:
+		return args[3:4]
+	case filePkg == "dto" && name == "add" && len(args) >= 3:
+		return args[2:3]
+	case (pkg == "i18n" || (pkg == "" && filePkg == "i18n")) && (name == "Text" || name == "Sprintf") && len(args) >= 2:
+		return args[1:2]
+	}
+	return nil
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
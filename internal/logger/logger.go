@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader — заголовок с ID запроса: входящий ID используется, если он
+// корректен, и в любом случае возвращается в ответе.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	loggerKey
+)
+
+// New создаёт логгер, который пишет в w записи уровня level и выше
+// в формате format: json или text.
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "text" {
+		return slog.New(slog.NewTextHandler(w, opts))
+	}
+	return slog.New(slog.NewJSONHandler(w, opts))
+}
+
+// WithRequestID сохраняет в контексте ID запроса и логгер base, который
+// добавляет этот ID к каждой записи.
+func WithRequestID(ctx context.Context, base *slog.Logger, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, requestID)
+	return context.WithValue(ctx, loggerKey, base.With("request_id", requestID))
+}
+
+// RequestID возвращает ID запроса из контекста или пустую строку.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// FromContext возвращает логгер запроса с его ID, а вне запроса — slog.Default().
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return log
+	}
+	return slog.Default()
+}
+
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (lrw *loggingResponseWriter) WriteHeader(code int) {
+	lrw.statusCode = code
+	lrw.ResponseWriter.WriteHeader(code)
+}
+
+// Middleware присваивает запросу ID, кладёт в контекст логгер с этим ID
+// и пишет в лог каждый запрос с его статусом и длительностью.
+func Middleware(base *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			reqID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(reqID) {
+				reqID = uuid.New().String()
+			}
+			w.Header().Set(RequestIDHeader, reqID)
+
+			lrw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			r = r.WithContext(WithRequestID(r.Context(), base, reqID))
+
+			next.ServeHTTP(lrw, r)
+
+			FromContext(r.Context()).Info("запрос обработан",
+				"method", r.Method,
+				"path", r.URL.String(),
+				"status", lrw.statusCode,
+				"duration", time.Since(start),
+			)
+		})
+	}
+}
+
+// validRequestID проверяет входящий X-Request-ID: непустой, не длиннее
+// maxRequestIDLength и из видимых символов ASCII, чтобы его можно было
+// безопасно писать в лог и возвращать в заголовке.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"incoming id is echoed", "edge-42", true},
+		{"missing id is generated", "", false},
+		{"id with spaces is replaced", "bad id", false},
+		{"too long id is replaced", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var handlerID string
+			handler := Middleware(New(&buf, "json", slog.LevelInfo))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerID = RequestID(r.Context())
+				FromContext(r.Context()).Warn("проверка")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			echoed := rec.Header().Get(RequestIDHeader)
+			if echoed == "" || echoed != handlerID {
+				t.Fatalf("%s = %q, request id in context = %q", RequestIDHeader, echoed, handlerID)
+			}
+			if (echoed == tt.incoming) != tt.keep {
+				t.Errorf("%s = %q for incoming %q, keep = %v", RequestIDHeader, echoed, tt.incoming, tt.keep)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("log lines = %d, want 2: %s", len(lines), buf.String())
+			}
+			for _, line := range lines {
+				var record map[string]any
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("запись лога не JSON: %v", err)
+				}
+				if record["request_id"] != echoed {
+					t.Errorf("request_id = %v, want %q", record["request_id"], echoed)
+				}
+			}
+		})
+	}
+}
+
+func TestFromContextWithoutRequest(t *testing.T) {
+	if FromContext(context.Background()) != slog.Default() {
+		t.Error("FromContext() вне запроса должен вернуть slog.Default()")
+	}
+	if RequestID(context.Background()) != "" {
+		t.Error("RequestID() вне запроса должен быть пустым")
+	}
+}
+
+func TestNewLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, "text", slog.LevelWarn)
+	log.Info("не попадёт в лог")
+	log.Warn("попадёт в лог")
+
+	if out := buf.String(); strings.Contains(out, "не попадёт") || !strings.Contains(out, "level=WARN") {
+		t.Errorf("log output = %q", out)
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+// BatchOperationKind — вид операции пакетного запроса.
+type BatchOperationKind string
+
+const (
+	BatchCreate BatchOperationKind = "create"
+	BatchUpdate BatchOperationKind = "update"
+	BatchDelete BatchOperationKind = "delete"
+)
+
+// BatchOperation — операция пакетного запроса над подпиской. Для delete
+// из Subscription используются только ID и Version.
+type BatchOperation struct {
+	Kind         BatchOperationKind
+	Subscription *Subscription
+}
+
+// BatchResult — результат операции: подписка после create/update или ошибка.
+type BatchResult struct {
+	Subscription *Subscription
+	Err          error
+}
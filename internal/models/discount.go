@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// DiscountKind — тип скидки: процент от начисления или фиксированная сумма в валюте подписки.
+type DiscountKind string
+
+const (
+	DiscountPercent DiscountKind = "percent"
+	DiscountFixed   DiscountKind = "fixed"
+)
+
+// SubscriptionDiscount — скидка, действующая с месяца StartMonth по EndMonth включительно.
+// Без EndMonth скидка бессрочная.
+type SubscriptionDiscount struct {
+	ID             int          `db:"id"`
+	SubscriptionID int          `db:"subscription_id"`
+	Kind           DiscountKind `db:"kind"`
+	Value          int          `db:"value"`
+	StartMonth     time.Time    `db:"start_month"`
+	EndMonth       *time.Time   `db:"end_month"`
+}
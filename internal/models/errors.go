@@ -2,4 +2,89 @@ package models
 
 import "errors"
 
-var ErrSubscriptionNotFound = errors.New("подписка не найдена")
+// ErrorCode — стабильный код доменной ошибки. Коды не меняются между версиями:
+// по ним транспортный слой выбирает ответ, а клиенты API различают ошибки.
+type ErrorCode string
+
+const (
+	CodeSubscriptionNotFound    ErrorCode = "SUBSCRIPTION_NOT_FOUND"
+	CodePauseNotFound           ErrorCode = "PAUSE_NOT_FOUND"
+	CodeExchangeRateNotFound    ErrorCode = "EXCHANGE_RATE_NOT_FOUND"
+	CodeInvalidPeriod           ErrorCode = "INVALID_PERIOD"
+	CodeInvalidValue            ErrorCode = "INVALID_VALUE"
+	CodePriceOutsidePeriod      ErrorCode = "PRICE_OUTSIDE_PERIOD"
+	CodeDiscountOutsidePeriod   ErrorCode = "DISCOUNT_OUTSIDE_PERIOD"
+	CodePauseOutsidePeriod      ErrorCode = "PAUSE_OUTSIDE_PERIOD"
+	CodeExchangeRateMissing     ErrorCode = "EXCHANGE_RATE_MISSING"
+	CodeInvalidStatusTransition ErrorCode = "INVALID_STATUS_TRANSITION"
+	CodePauseOverlap            ErrorCode = "PAUSE_OVERLAP"
+	CodePauseInProgress         ErrorCode = "PAUSE_IN_PROGRESS"
+	CodeDuplicateSubscription   ErrorCode = "DUPLICATE_SUBSCRIPTION"
+	CodeConflict                ErrorCode = "CONFLICT"
+	CodeVersionMismatch         ErrorCode = "VERSION_MISMATCH"
+	CodeInvalidPatch            ErrorCode = "INVALID_PATCH"
+	CodeBatchRolledBack         ErrorCode = "BATCH_ROLLED_BACK"
+)
+
+// Error — доменная ошибка: код, сообщение и исходная причина (например, ошибка
+// PostgreSQL). Ошибки сравниваются по коду, поэтому errors.Is(err, ErrSubscriptionNotFound)
+// верно для любой ошибки с кодом SUBSCRIPTION_NOT_FOUND.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap возвращает ошибку с тем же кодом и сообщением и причиной cause.
+func (e *Error) Wrap(cause error) *Error {
+	return &Error{Code: e.Code, Message: e.Message, Err: cause}
+}
+
+// CodeOf возвращает код первой доменной ошибки в цепочке err или пустую строку.
+func CodeOf(err error) ErrorCode {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return ""
+}
+
+var (
+	ErrSubscriptionNotFound  = NewError(CodeSubscriptionNotFound, "подписка не найдена")
+	ErrPriceOutsidePeriod    = NewError(CodePriceOutsidePeriod, "дата изменения цены вне периода подписки")
+	ErrDiscountOutsidePeriod = NewError(CodeDiscountOutsidePeriod, "период скидки вне периода подписки")
+	ErrInvalidTransition     = NewError(CodeInvalidStatusTransition, "недопустимая смена статуса подписки")
+	ErrPauseNotFound         = NewError(CodePauseNotFound, "пауза подписки не найдена")
+	ErrPauseOutsidePeriod    = NewError(CodePauseOutsidePeriod, "пауза вне периода подписки")
+	ErrPauseOverlap          = NewError(CodePauseOverlap, "пауза пересекается с другой паузой подписки")
+	ErrPauseInProgress       = NewError(CodePauseInProgress, "пауза подписки ещё не закончилась")
+	ErrVersionMismatch       = NewError(CodeVersionMismatch, "подписка изменена с момента чтения")
+	ErrBatchRolledBack       = NewError(CodeBatchRolledBack, "пакет операций отменён из-за ошибки в операции")
+	ErrInvalidPatch          = NewError(CodeInvalidPatch, "некорректный патч подписки")
+	ErrExchangeRateNotFound  = NewError(CodeExchangeRateNotFound, "курс валюты не найден")
+	ErrExchangeRateMissing   = NewError(CodeExchangeRateMissing, "нет курсов валют для пересчёта")
+	ErrInvalidPeriod         = NewError(CodeInvalidPeriod, "конец периода раньше его начала")
+	ErrInvalidValue          = NewError(CodeInvalidValue, "недопустимое значение поля")
+	ErrDuplicateSubscription = NewError(CodeDuplicateSubscription, "такая подписка уже существует")
+	ErrConflict              = NewError(CodeConflict, "конфликт с параллельным изменением")
+)
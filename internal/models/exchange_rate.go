@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExchangeRate — курс валюты к рублю, действующий в течение месяца Month.
+type ExchangeRate struct {
+	Currency string    `db:"currency"`
+	Month    time.Time `db:"month"`
+	Rate     float64   `db:"rate"`
+}
+
+// ExchangeRatesFilter — выборка курсов за месяцы [From; To].
+// Пустой список валют означает все валюты.
+type ExchangeRatesFilter struct {
+	Currencies []string
+	From       time.Time
+	To         time.Time
+}
+
+type CurrencyMonth struct {
+	Currency string
+	Month    time.Time
+}
+
+// MissingRatesError перечисляет курсы, без которых не удалось пересчитать стоимость.
+type MissingRatesError struct {
+	Missing []CurrencyMonth
+}
+
+func NewMissingRatesError(missing []CurrencyMonth) *MissingRatesError {
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Currency != missing[j].Currency {
+			return missing[i].Currency < missing[j].Currency
+		}
+		return missing[i].Month.Before(missing[j].Month)
+	})
+	return &MissingRatesError{Missing: missing}
+}
+
+func (e *MissingRatesError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i, m := range e.Missing {
+		missing[i] = fmt.Sprintf("%s %s", m.Currency, m.Month.Format("01-2006"))
+	}
+	return fmt.Sprintf("%v: %s", ErrExchangeRateMissing, strings.Join(missing, ", "))
+}
+
+func (e *MissingRatesError) Unwrap() error {
+	return ErrExchangeRateMissing
+}
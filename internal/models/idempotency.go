@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// IdempotencyKey — ключ из заголовка Idempotency-Key вместе с хешем запроса
+// и ответом на него. Ключи разных клиентов (Client) не пересекаются.
+// StatusCode 0 означает, что запрос ещё выполняется: до LockedUntil ключ
+// занят им, после — повтор того же запроса может забрать ключ себе.
+// ResponseHeader — заголовки ответа в JSON.
+type IdempotencyKey struct {
+	Client         string    `db:"client"`
+	Key            string    `db:"key"`
+	RequestHash    string    `db:"request_hash"`
+	StatusCode     int       `db:"status_code"`
+	ResponseHeader []byte    `db:"response_header"`
+	ResponseBody   []byte    `db:"response_body"`
+	LockedUntil    time.Time `db:"locked_until"`
+	ExpiresAt      time.Time `db:"expires_at"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import "strconv"
+
+// SortField — поле сортировки списка, Desc — по убыванию.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SortValueType — тип значения поля сортировки. Совпадает с типом PostgreSQL,
+// к которому приводится значение из курсора.
+type SortValueType string
+
+const (
+	SortInt  SortValueType = "int"
+	SortDate SortValueType = "date"
+	SortText SortValueType = "text"
+	SortUUID SortValueType = "uuid"
+)
+
+// SortableFields — поля, по которым разрешена сортировка списка подписок, и типы
+// их значений. Названия совпадают с колонками таблицы subscriptions.
+var SortableFields = map[string]SortValueType{
+	"id":           SortInt,
+	"price":        SortInt,
+	"start_date":   SortDate,
+	"end_date":     SortDate,
+	"service_name": SortText,
+	"user_id":      SortUUID,
+}
+
+// Cursor — позиция в списке подписок для keyset-пагинации: сортировка
+// и значения её полей у последней выданной подписки.
+type Cursor struct {
+	Sort   []SortField
+	Values []string
+}
+
+// SortWithID дополняет сортировку полем id, чтобы порядок был однозначным.
+// Поля после id ничего не меняют и отбрасываются.
+func SortWithID(sort []SortField) []SortField {
+	result := make([]SortField, 0, len(sort)+1)
+	for _, field := range sort {
+		result = append(result, field)
+		if field.Field == "id" {
+			return result
+		}
+	}
+	return append(result, SortField{Field: "id"})
+}
+
+// CursorAfter возвращает курсор, указывающий на позицию сразу после подписки sub.
+func CursorAfter(sub *Subscription, sort []SortField) *Cursor {
+	sort = SortWithID(sort)
+	cursor := &Cursor{Sort: sort, Values: make([]string, len(sort))}
+	for i, field := range sort {
+		cursor.Values[i] = sub.sortValue(field.Field)
+	}
+	return cursor
+}
+
+// sortValue возвращает значение поля сортировки в виде, понятном PostgreSQL.
+// Бессрочная подписка сортируется как подписка с end_date = infinity.
+func (s *Subscription) sortValue(field string) string {
+	switch field {
+	case "price":
+		return strconv.Itoa(s.Price)
+	case "start_date":
+		return s.StartDate.Format("2006-01-02")
+	case "end_date":
+		if s.EndDate == nil {
+			return "infinity"
+		}
+		return s.EndDate.Format("2006-01-02")
+	case "service_name":
+		return s.ServiceName
+	case "user_id":
+		return s.UserID.String()
+	default:
+		return strconv.Itoa(s.ID)
+	}
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// SubscriptionStatus — состояние подписки. В базе хранятся active, paused и cancelled,
+// expired вычисляется по end_date.
+type SubscriptionStatus string
+
+const (
+	StatusActive    SubscriptionStatus = "active"
+	StatusPaused    SubscriptionStatus = "paused"
+	StatusCancelled SubscriptionStatus = "cancelled"
+	StatusExpired   SubscriptionStatus = "expired"
+)
+
+// SubscriptionPause — приостановка подписки на месяцы [StartMonth; EndMonth].
+// Без EndMonth пауза ещё не закончилась.
+type SubscriptionPause struct {
+	ID             int        `db:"id"`
+	SubscriptionID int        `db:"subscription_id"`
+	StartMonth     time.Time  `db:"start_month"`
+	EndMonth       *time.Time `db:"end_month"`
+}
+
+// StatusAt возвращает статус подписки на день today: действующая или приостановленная
+// подписка, последний день которой уже прошёл, считается истёкшей.
+func (s *Subscription) StatusAt(today time.Time) SubscriptionStatus {
+	if s.Status == StatusCancelled || s.EndDate == nil {
+		return s.Status
+	}
+
+	lastDay := *s.EndDate
+	if s.DatePrecision != PrecisionDay {
+		lastDay = time.Date(lastDay.Year(), lastDay.Month()+1, 0, 0, 0, 0, 0, lastDay.Location())
+	}
+	if lastDay.Before(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, lastDay.Location())) {
+		return StatusExpired
+	}
+	return s.Status
+}
+
+// StatusAfterEdit возвращает статус подписки edited, полученной изменением полей s.
+// Отменённая подписка, у которой сняли end_date или перенесли его позже даты
+// отмены, снова действует. Остальные статусы меняются только ручками статуса.
+func (s *Subscription) StatusAfterEdit(edited *Subscription) SubscriptionStatus {
+	if s.Status != StatusCancelled {
+		return s.Status
+	}
+	if edited.EndDate == nil || (s.EndDate != nil && edited.EndDate.After(*s.EndDate)) {
+		return StatusActive
+	}
+	return s.Status
+}
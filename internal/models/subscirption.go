@@ -6,18 +6,121 @@ import (
 	"github.com/google/uuid"
 )
 
+type BillingPeriod string
+
+const (
+	BillingWeekly    BillingPeriod = "weekly"
+	BillingMonthly   BillingPeriod = "monthly"
+	BillingQuarterly BillingPeriod = "quarterly"
+	BillingYearly    BillingPeriod = "yearly"
+)
+
+// DatePrecision определяет, с какой точностью заданы даты подписки.
+// При точности до месяца подписка действует с первого дня месяца start_date
+// до последнего дня месяца end_date.
+type DatePrecision string
+
+const (
+	PrecisionMonth DatePrecision = "month"
+	PrecisionDay   DatePrecision = "day"
+)
+
+// Proration — политика начисления за неполные месяцы подписок с точностью до дня.
+type Proration string
+
+const (
+	ProrationFull    Proration = "full"
+	ProrationDaily   Proration = "daily"
+	ProrationRenewal Proration = "renewal"
+)
+
+// DefaultCurrency — валюта цен подписок и расчётов, если другая не указана.
+// Курсы остальных валют хранятся относительно неё.
+const DefaultCurrency = "RUB"
+
+// Subscription — подписка пользователя. TrialEndDate — последний день (или месяц,
+// при точности до месяца) бесплатного пробного периода. Version увеличивается
+// при каждом изменении подписки и служит для оптимистичной блокировки.
 type Subscription struct {
-	ID          int        `db:"id"`
-	ServiceName string     `db:"service_name"`
-	Price       int        `db:"price"`
-	UserID      uuid.UUID  `db:"user_id"`
-	StartDate   time.Time  `db:"start_date"`
-	EndDate     *time.Time `db:"end_date"`
+	ID            int                `db:"id"`
+	ServiceName   string             `db:"service_name"`
+	Price         int                `db:"price"`
+	UserID        uuid.UUID          `db:"user_id"`
+	StartDate     time.Time          `db:"start_date"`
+	EndDate       *time.Time         `db:"end_date"`
+	BillingPeriod BillingPeriod      `db:"billing_period"`
+	DatePrecision DatePrecision      `db:"date_precision"`
+	Currency      string             `db:"currency"`
+	TrialEndDate  *time.Time         `db:"trial_end_date"`
+	Status        SubscriptionStatus `db:"status"`
+	Version       int                `db:"version"`
+
+	Prices    []SubscriptionPrice    `db:"-"`
+	Discounts []SubscriptionDiscount `db:"-"`
+	Pauses    []SubscriptionPause    `db:"-"`
 }
 
 type ListSubscriptionsParams struct {
-	StartDate   time.Time  `db:"start_date"`
-	EndDate     time.Time  `db:"end_date"`
-	UserID      *uuid.UUID `db:"user_id"`
-	ServiceName *string    `db:"service_name"`
+	StartDate     time.Time      `db:"start_date"`
+	EndDate       time.Time      `db:"end_date"`
+	UserID        *uuid.UUID     `db:"user_id"`
+	ServiceName   *string        `db:"service_name"`
+	BillingPeriod *BillingPeriod `db:"billing_period"`
+
+	Options CostOptions `db:"-"`
+}
+
+// SubscriptionFilter — фильтры, сортировка и пагинация списка подписок.
+type SubscriptionFilter struct {
+	UserID            *uuid.UUID
+	ServiceName       *string
+	ServiceNamePrefix *string
+	MinPrice          *int
+	MaxPrice          *int
+	// ActiveOn — месяц, в котором подписка должна действовать.
+	ActiveOn  *time.Time
+	OpenEnded bool
+
+	Sort   []SortField
+	Limit  int
+	Offset int
+	// After — курсор keyset-пагинации: список начинается после этой позиции, Offset не используется.
+	After *Cursor
+}
+
+// CostOptions — параметры расчёта стоимости, не влияющие на выборку подписок.
+type CostOptions struct {
+	// Normalize пересчитывает цену любого периода оплаты в месячный эквивалент.
+	Normalize bool
+	Proration Proration
+	// Currency — валюта, в которую пересчитываются начисления.
+	Currency string
+}
+
+// MonthlyCost — начисление за месяц: Gross до скидок, Discount — сумма скидок,
+// Cost — итог к оплате.
+type MonthlyCost struct {
+	Month    time.Time
+	Gross    int
+	Discount int
+	Cost     int
+}
+
+type CostGroupBy string
+
+const (
+	GroupByServiceName CostGroupBy = "service_name"
+	GroupByUserID      CostGroupBy = "user_id"
+)
+
+type GroupCost struct {
+	Key  string `db:"group_key"`
+	Cost int    `db:"total_cost"`
+}
+
+type SubscriptionPrice struct {
+	ID             int       `db:"id"`
+	SubscriptionID int       `db:"subscription_id"`
+	Price          int       `db:"price"`
+	EffectiveFrom  time.Time `db:"effective_from"`
 }
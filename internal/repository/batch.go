@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// Batch выполняет операции над подписками в одной транзакции. При atomic ошибка
+// любой операции откатывает весь пакет и возвращается обёрнутой в
+// models.ErrBatchRolledBack. Иначе каждая операция выполняется в своей точке
+// сохранения: ошибка откатывает только её и попадает в её результат.
+func (r *SubsRepo) Batch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error) {
+	results := make([]models.BatchResult, len(ops))
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		for i, op := range ops {
+			if atomic {
+				results[i] = applyOperation(ctx, tx, op)
+				if results[i].Err != nil {
+					return fmt.Errorf("%w: операция %d: %w", models.ErrBatchRolledBack, i, results[i].Err)
+				}
+				continue
+			}
+
+			if _, err := tx.ExecContext(ctx, `SAVEPOINT batch_operation`); err != nil {
+				return fmt.Errorf("не удалось создать точку сохранения: %w", err)
+			}
+			results[i] = applyOperation(ctx, tx, op)
+			if results[i].Err != nil {
+				logger.FromContext(ctx).Debug("операция пакета откачена до точки сохранения", "index", i, "error", results[i].Err)
+				if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch_operation`); err != nil {
+					return fmt.Errorf("не удалось откатить операцию %d: %w", i, err)
+				}
+			}
+			if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT batch_operation`); err != nil {
+				return fmt.Errorf("не удалось освободить точку сохранения: %w", err)
+			}
+		}
+		return nil
+	})
+	return results, err
+}
+
+func applyOperation(ctx context.Context, tx *sqlx.Tx, op models.BatchOperation) models.BatchResult {
+	sub := op.Subscription
+	var err error
+	switch op.Kind {
+	case models.BatchCreate:
+		err = createSubscription(ctx, tx, sub)
+	case models.BatchUpdate:
+		if err = updateInBatch(ctx, tx, sub); err == nil {
+			sub, err = getByID(ctx, tx, sub.ID)
+		}
+	case models.BatchDelete:
+		err = deleteSubscription(ctx, tx, sub.ID, sub.Version)
+		sub = nil
+	default:
+		err = fmt.Errorf("неизвестная операция пакета: %s", op.Kind)
+	}
+
+	if err != nil {
+		return models.BatchResult{Err: err}
+	}
+	return models.BatchResult{Subscription: sub}
+}
+
+// updateInBatch заменяет подписку целиком, как SubsService.Update: статус
+// считается по заблокированной до конца транзакции текущей подписке.
+func updateInBatch(ctx context.Context, tx *sqlx.Tx, sub *models.Subscription) error {
+	var current models.Subscription
+	if err := tx.GetContext(ctx, &current, `SELECT * FROM subscriptions WHERE id = $1 FOR UPDATE`, sub.ID); err != nil {
+		if isNoRows(err) {
+			return fmt.Errorf("%w: изменение подписки id %d", models.ErrSubscriptionNotFound, sub.ID)
+		}
+		return fmt.Errorf("ошибка блокировки подписки: %w", err)
+	}
+
+	sub.Status = current.StatusAfterEdit(sub)
+	return updateSubscription(ctx, tx, sub)
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/lib/pq"
+)
+
+func (r *SubsRepo) TotalCost(ctx context.Context, params *models.ListSubscriptionsParams) (int, error) {
+	charges, args := monthlyChargesQuery(params)
+	query := `
+		WITH charges AS MATERIALIZED (` + charges + `)
+		SELECT COALESCE(SUM(cost), 0) AS total_cost, ` + missingRatesColumn + `
+		FROM charges;
+	`
+
+	var result struct {
+		TotalCost    int            `db:"total_cost"`
+		MissingRates pq.StringArray `db:"missing_rates"`
+	}
+	if err := r.db.GetContext(ctx, &result, query, args...); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета стоимости подписок: %w", err)
+	}
+	if err := missingRatesError(result.MissingRates); err != nil {
+		return 0, err
+	}
+	return result.TotalCost, nil
+}
+
+func (r *SubsRepo) TotalCostGroupedBy(ctx context.Context, params *models.ListSubscriptionsParams, groupBy models.CostGroupBy) ([]models.GroupCost, error) {
+	var groupColumn string
+	switch groupBy {
+	case models.GroupByServiceName:
+		groupColumn = "service_name"
+	case models.GroupByUserID:
+		groupColumn = "user_id"
+	default:
+		return nil, fmt.Errorf("неизвестное поле группировки: %s", groupBy)
+	}
+
+	charges, args := monthlyChargesQuery(params)
+	query := `
+		WITH charges AS MATERIALIZED (` + charges + `)
+		SELECT ` + groupColumn + `::text AS group_key, SUM(cost) AS total_cost, ` + missingRatesColumn + `
+		FROM charges
+		GROUP BY group_key
+		ORDER BY group_key;
+	`
+
+	var rows []struct {
+		models.GroupCost
+		MissingRates pq.StringArray `db:"missing_rates"`
+	}
+	err := r.db.SelectContext(ctx, &rows, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета стоимости подписок по группам: %w", err)
+	}
+	if len(rows) > 0 {
+		if err := missingRatesError(rows[0].MissingRates); err != nil {
+			return nil, err
+		}
+	}
+
+	groups := make([]models.GroupCost, len(rows))
+	for i, row := range rows {
+		groups[i] = row.GroupCost
+	}
+	return groups, nil
+}
+
+// monthlyChargesQuery строит подзапрос, который разворачивает каждую подписку
+// в строки по месяцам внутри окна [StartDate; EndDate] с начислением за каждый месяц.
+// Логика начислений повторяет эталонный расчёт из пакета billing.
+func monthlyChargesQuery(params *models.ListSubscriptionsParams) (string, []any) {
+	opts := params.Options
+
+	charge := `
+		CASE s.billing_period
+			WHEN 'weekly' THEN prices.price * GREATEST(
+				(months.active_to - s.start_date) / 7 - (months.active_from - s.start_date + 6) / 7 + 1, 0)
+			WHEN 'quarterly' THEN CASE WHEN months.month_offset % 3 = 0 THEN prices.price ELSE 0 END
+			WHEN 'yearly' THEN CASE WHEN months.month_offset % 12 = 0 THEN prices.price ELSE 0 END
+			ELSE prices.price
+		END`
+	monthly, weekly := `s.billing_period = 'monthly'`, `s.billing_period = 'weekly'`
+	if opts.Normalize {
+		charge = `
+		CASE s.billing_period
+			WHEN 'weekly' THEN round(prices.price * 52 / 12.0)::int
+			WHEN 'quarterly' THEN round(prices.price / 3.0)::int
+			WHEN 'yearly' THEN round(prices.price / 12.0)::int
+			ELSE prices.price
+		END`
+		monthly, weekly = `TRUE`, `FALSE`
+	}
+
+	cost := charge
+	onRenewal := `CASE WHEN months.renewal_date BETWEEN months.active_from AND months.active_to THEN ` + charge + ` ELSE 0 END`
+	switch opts.Proration {
+	case models.ProrationDaily:
+		cost = `
+		CASE
+			WHEN ` + monthly + ` THEN round((` + charge + `) * (months.active_to - months.active_from + 1)::numeric / months.month_days)::int
+			WHEN ` + weekly + ` THEN ` + charge + `
+			ELSE ` + onRenewal + `
+		END`
+	case models.ProrationRenewal:
+		cost = `
+		CASE
+			WHEN ` + weekly + ` THEN ` + charge + `
+			ELSE ` + onRenewal + `
+		END`
+	}
+
+	query := `
+		SELECT s.id, s.service_name, s.user_id, s.currency, months.month,
+			CASE
+				WHEN months.active_from > months.active_to OR EXISTS (
+					SELECT 1 FROM subscription_pauses p
+					WHERE p.subscription_id = s.id
+						AND p.start_month <= months.month
+						AND (p.end_month IS NULL OR p.end_month >= months.month)
+				) THEN 0
+				ELSE ` + cost + `
+			END AS gross
+		FROM subscriptions s
+		CROSS JOIN LATERAL (
+			SELECT (CASE
+				WHEN s.end_date IS NULL OR s.date_precision = 'day' THEN s.end_date
+				ELSE (date_trunc('month', s.end_date::timestamp) + interval '1 month - 1 day')::date
+			END) AS active_until,
+			(CASE
+				WHEN s.trial_end_date IS NULL OR s.date_precision = 'day' THEN s.trial_end_date
+				ELSE (date_trunc('month', s.trial_end_date::timestamp) + interval '1 month - 1 day')::date
+			END) AS trial_until
+		) AS bounds
+		CROSS JOIN LATERAL (
+			SELECT month,
+				month_offset,
+				month_days,
+				GREATEST(month::date, s.start_date, bounds.trial_until + 1) AS active_from,
+				LEAST(month::date + month_days - 1, COALESCE(bounds.active_until, 'infinity'::date)) AS active_to,
+				month::date + LEAST(EXTRACT(DAY FROM s.start_date)::int, month_days) - 1 AS renewal_date
+			FROM generate_series(
+				GREATEST(date_trunc('month', s.start_date::timestamp), $1::date),
+				LEAST(date_trunc('month', COALESCE(s.end_date, $2::date)::timestamp), $2::date),
+				interval '1 month'
+			) AS month
+			CROSS JOIN LATERAL (
+				SELECT (
+					(EXTRACT(YEAR FROM month) - EXTRACT(YEAR FROM s.start_date)) * 12
+					+ EXTRACT(MONTH FROM month) - EXTRACT(MONTH FROM s.start_date)
+				)::int AS month_offset,
+				EXTRACT(DAY FROM month + interval '1 month - 1 day')::int AS month_days
+			) AS calendar
+		) AS months
+		CROSS JOIN LATERAL (
+			SELECT COALESCE((
+				SELECT p.price FROM subscription_prices p
+				WHERE p.subscription_id = s.id AND p.effective_from <= months.month
+				ORDER BY p.effective_from DESC
+				LIMIT 1
+			), s.price) AS price
+		) AS prices
+		WHERE s.start_date < $2::date + interval '1 month'
+			AND (s.end_date IS NULL OR s.end_date >= $1)
+	`
+
+	currency := opts.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+
+	where := newWhereBuilder(params.StartDate, params.EndDate, currency)
+	where.addCostFilters(params)
+	query += " AND " + where.and()
+
+	// Из начислений вычитаются скидки месяца, затем они пересчитываются в валюту $3
+	// по курсам месяца начисления. Если курса нет, в missing_rates попадает
+	// недостающая пара "валюта месяц".
+	query = `
+		SELECT charges.id, charges.service_name, charges.user_id, charges.month,
+			CASE
+				WHEN discounts.net = 0 OR charges.currency = $3::text THEN discounts.net
+				ELSE round(discounts.net * rates.source_rate / rates.target_rate)::int
+			END AS cost,
+			CASE
+				WHEN discounts.net = 0 OR charges.currency = $3::text THEN '{}'::text[]
+				ELSE array_remove(ARRAY[
+					CASE WHEN rates.source_rate IS NULL THEN charges.currency || ' ' || to_char(charges.month, 'MM-YYYY') END,
+					CASE WHEN rates.target_rate IS NULL THEN $3::text || ' ' || to_char(charges.month, 'MM-YYYY') END
+				], NULL)
+			END AS missing_rates
+		FROM (` + query + `) AS charges
+		CROSS JOIN LATERAL (
+			SELECT (charges.gross - LEAST(charges.gross, COALESCE(SUM(
+				CASE d.kind WHEN 'percent' THEN round(charges.gross * d.value / 100.0)::int ELSE d.value END
+			), 0)))::int AS net
+			FROM subscription_discounts d
+			WHERE d.subscription_id = charges.id
+				AND d.start_month <= charges.month
+				AND (d.end_month IS NULL OR d.end_month >= charges.month)
+		) AS discounts
+		CROSS JOIN LATERAL (
+			SELECT
+				CASE WHEN charges.currency = '` + models.DefaultCurrency + `' THEN 1 ELSE (
+					SELECT rate FROM exchange_rates
+					WHERE currency = charges.currency AND month = charges.month
+				) END AS source_rate,
+				CASE WHEN $3::text = '` + models.DefaultCurrency + `' THEN 1 ELSE (
+					SELECT rate FROM exchange_rates
+					WHERE currency = $3::text AND month = charges.month
+				) END AS target_rate
+		) AS rates
+	`
+	return query, where.args
+}
+
+// missingRatesColumn собирает недостающие курсы всех начислений из CTE charges.
+const missingRatesColumn = `ARRAY(
+	SELECT DISTINCT unnest(missing_rates) FROM charges ORDER BY 1
+) AS missing_rates`
+
+func missingRatesError(missingRates []string) error {
+	if len(missingRates) == 0 {
+		return nil
+	}
+
+	missing := make([]models.CurrencyMonth, 0, len(missingRates))
+	for _, rate := range missingRates {
+		currency, monthStr, _ := strings.Cut(rate, " ")
+		month, err := time.Parse("01-2006", monthStr)
+		if err != nil {
+			return fmt.Errorf("некорректный недостающий курс %q: %w", rate, err)
+		}
+		missing = append(missing, models.CurrencyMonth{Currency: currency, Month: month})
+	}
+	return models.NewMissingRatesError(missing)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/lib/pq"
+)
+
+func (r *SubsRepo) AddDiscount(ctx context.Context, discount *models.SubscriptionDiscount) error {
+	query := `
+        INSERT INTO subscription_discounts (subscription_id, kind, value, start_month, end_month)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+
+	err := r.db.QueryRowContext(ctx, query, discount.SubscriptionID, discount.Kind, discount.Value, discount.StartMonth, discount.EndMonth).Scan(&discount.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось записать скидку подписки: %w", dbError(err))
+	}
+	return nil
+}
+
+func (r *SubsRepo) ListDiscounts(ctx context.Context, subIDs []int) ([]models.SubscriptionDiscount, error) {
+	query := `
+        SELECT * FROM subscription_discounts
+        WHERE subscription_id = ANY($1)
+        ORDER BY subscription_id, start_month, id
+    `
+
+	var discounts []models.SubscriptionDiscount
+	if err := r.db.SelectContext(ctx, &discounts, query, pq.Array(subIDs)); err != nil {
+		return nil, fmt.Errorf("ошибка получения скидок: %w", err)
+	}
+	return discounts, nil
+}
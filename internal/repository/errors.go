@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/lib/pq"
+)
+
+// Ограничения, нарушение которых означает неверный период (конец раньше начала).
+var periodConstraints = map[string]bool{
+	"subscriptions_period_check":       true,
+	"subscriptions_trial_period_check": true,
+	"subscriptions_trial_end_check":    true,
+	"subscription_discounts_check":     true,
+	"subscription_pauses_check":        true,
+}
+
+const duplicateSubscriptionConstraint = "subscriptions_user_service_start_key"
+
+// dbError переводит ошибки ограничений PostgreSQL в доменные ошибки с исходной
+// ошибкой в качестве причины. Остальные ошибки возвращаются без изменений.
+func dbError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		if pqErr.Constraint == duplicateSubscriptionConstraint {
+			return models.ErrDuplicateSubscription.Wrap(err)
+		}
+		return models.ErrConflict.Wrap(err)
+	case "check_violation":
+		if periodConstraints[pqErr.Constraint] {
+			return models.ErrInvalidPeriod.Wrap(err)
+		}
+		return models.ErrInvalidValue.Wrap(err)
+	case "foreign_key_violation":
+		return models.ErrSubscriptionNotFound.Wrap(err)
+	case "exclusion_violation", "serialization_failure", "deadlock_detected":
+		return models.ErrConflict.Wrap(err)
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/lib/pq"
+)
+
+func TestDBError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want models.ErrorCode
+	}{
+		{"duplicate subscription", &pq.Error{Code: "23505", Constraint: duplicateSubscriptionConstraint}, models.CodeDuplicateSubscription},
+		{"other unique violation", &pq.Error{Code: "23505", Constraint: "subscription_prices_subscription_id_effective_from_key"}, models.CodeConflict},
+		{"period check", &pq.Error{Code: "23514", Constraint: "subscriptions_period_check"}, models.CodeInvalidPeriod},
+		{"trial end check", &pq.Error{Code: "23514", Constraint: "subscriptions_trial_end_check"}, models.CodeInvalidPeriod},
+		{"value check", &pq.Error{Code: "23514", Constraint: "subscriptions_price_check"}, models.CodeInvalidValue},
+		{"foreign key", &pq.Error{Code: "23503"}, models.CodeSubscriptionNotFound},
+		{"serialization failure", &pq.Error{Code: "40001"}, models.CodeConflict},
+		{"syntax error", &pq.Error{Code: "42601"}, ""},
+		{"not a pq error", errors.New("соединение закрыто"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("не удалось записать данные подписки: %w", dbError(tt.err))
+			if got := models.CodeOf(err); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("dbError() lost the cause: %v", err)
+			}
+		})
+	}
+}
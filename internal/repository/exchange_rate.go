@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/lib/pq"
+)
+
+func (r *SubsRepo) SaveExchangeRate(ctx context.Context, rate *models.ExchangeRate) error {
+	query := `
+        INSERT INTO exchange_rates (currency, month, rate)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (currency, month) DO UPDATE SET rate = EXCLUDED.rate
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, rate.Currency, rate.Month, rate.Rate); err != nil {
+		return fmt.Errorf("не удалось записать курс валюты: %w", dbError(err))
+	}
+	return nil
+}
+
+func (r *SubsRepo) DeleteExchangeRate(ctx context.Context, currency string, month time.Time) error {
+	query := `DELETE FROM exchange_rates WHERE currency = $1 AND month = $2`
+
+	res, err := r.db.ExecContext(ctx, query, currency, month)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления курса валюты: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("не удалось удалить курс валюты: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: %s %s", models.ErrExchangeRateNotFound, currency, month.Format("01-2006"))
+	}
+	return nil
+}
+
+func (r *SubsRepo) ListExchangeRates(ctx context.Context, filter *models.ExchangeRatesFilter) ([]models.ExchangeRate, error) {
+	query := `
+        SELECT * FROM exchange_rates
+        WHERE month BETWEEN $1 AND $2
+            AND (cardinality($3::text[]) = 0 OR currency = ANY($3))
+        ORDER BY currency, month
+    `
+
+	var rates []models.ExchangeRate
+	err := r.db.SelectContext(ctx, &rates, query, filter.From, filter.To, pq.Array(filter.Currencies))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения курсов валют: %w", err)
+	}
+	return rates, nil
+}
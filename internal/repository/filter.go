@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// whereBuilder собирает условия WHERE с позиционными параметрами $n.
+// Начальные аргументы занимают первые номера, условия добавляются после них.
+type whereBuilder struct {
+	conditions []string
+	args       []any
+}
+
+func newWhereBuilder(args ...any) *whereBuilder {
+	return &whereBuilder{args: args}
+}
+
+// add добавляет условие, в котором каждый ? заменяется номером следующего аргумента.
+func (b *whereBuilder) add(condition string, args ...any) {
+	for _, arg := range args {
+		condition = strings.Replace(condition, "?", b.arg(arg), 1)
+	}
+	b.conditions = append(b.conditions, condition)
+}
+
+// arg добавляет аргумент вне условий (например, для LIMIT) и возвращает его номер $n.
+func (b *whereBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// and возвращает условия, объединённые через AND, или TRUE, если условий нет.
+func (b *whereBuilder) and() string {
+	if len(b.conditions) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(b.conditions, " AND ")
+}
+
+// addCostFilters добавляет фильтры подписок из параметров расчёта стоимости.
+func (b *whereBuilder) addCostFilters(params *models.ListSubscriptionsParams) {
+	if params.UserID != nil {
+		b.add("user_id = ?", *params.UserID)
+	}
+	if params.ServiceName != nil {
+		b.add("service_name = ?", *params.ServiceName)
+	}
+	if params.BillingPeriod != nil {
+		b.add("billing_period = ?", *params.BillingPeriod)
+	}
+}
+
+// subscriptionListWhere строит условия для списка подписок.
+func subscriptionListWhere(filter *models.SubscriptionFilter) *whereBuilder {
+	b := newWhereBuilder()
+	if filter.UserID != nil {
+		b.add("user_id = ?", *filter.UserID)
+	}
+	if filter.ServiceName != nil {
+		b.add("service_name = ?", *filter.ServiceName)
+	}
+	if filter.ServiceNamePrefix != nil {
+		b.add(`service_name LIKE ? || '%'`, escapeLike(*filter.ServiceNamePrefix))
+	}
+	if filter.MinPrice != nil {
+		b.add("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		b.add("price <= ?", *filter.MaxPrice)
+	}
+	if filter.ActiveOn != nil {
+		b.add("start_date < ?::date + interval '1 month' AND (end_date IS NULL OR end_date >= ?)", *filter.ActiveOn, *filter.ActiveOn)
+	}
+	if filter.OpenEnded {
+		b.add("end_date IS NULL")
+	}
+	return b
+}
+
+type sortColumn struct{ expr, cast string }
+
+// sortColumnFor возвращает выражение для ORDER BY поля сортировки из
+// models.SortableFields и тип, к которому приводится значение из курсора.
+// Бессрочные подписки сортируются как подписки с end_date = infinity.
+func sortColumnFor(field string) (sortColumn, bool) {
+	valueType, ok := models.SortableFields[field]
+	if !ok {
+		return sortColumn{}, false
+	}
+	if field == "end_date" {
+		return sortColumn{"COALESCE(end_date, 'infinity'::date)", string(valueType)}, true
+	}
+	return sortColumn{field, string(valueType)}, true
+}
+
+// orderBy строит ORDER BY по полям сортировки, дополненным id.
+func orderBy(sort []models.SortField) (string, error) {
+	sort = models.SortWithID(sort)
+	terms := make([]string, len(sort))
+	for i, field := range sort {
+		column, ok := sortColumnFor(field.Field)
+		if !ok {
+			return "", fmt.Errorf("неизвестное поле сортировки: %s", field.Field)
+		}
+
+		terms[i] = column.expr + " ASC"
+		if field.Desc {
+			terms[i] = column.expr + " DESC"
+		}
+	}
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// addAfter добавляет условие keyset-пагинации: строки, идущие в порядке сортировки
+// курсора после его позиции. Для сортировки (a, b) это
+// a > va OR (a = va AND b > vb), с < для полей по убыванию.
+func (b *whereBuilder) addAfter(cursor *models.Cursor) error {
+	if len(cursor.Sort) == 0 || len(cursor.Sort) != len(cursor.Values) {
+		return fmt.Errorf("некорректный курсор: %d полей и %d значений", len(cursor.Sort), len(cursor.Values))
+	}
+
+	var alternatives []string
+	var args []any
+	for i, field := range cursor.Sort {
+		column, ok := sortColumnFor(field.Field)
+		if !ok {
+			return fmt.Errorf("неизвестное поле сортировки в курсоре: %s", field.Field)
+		}
+
+		op := ">"
+		if field.Desc {
+			op = "<"
+		}
+
+		terms := make([]string, 0, i+1)
+		for j := range i {
+			prev, _ := sortColumnFor(cursor.Sort[j].Field)
+			terms = append(terms, prev.expr+" = ?::"+prev.cast)
+			args = append(args, cursor.Values[j])
+		}
+		terms = append(terms, column.expr+" "+op+" ?::"+column.cast)
+		args = append(args, cursor.Values[i])
+
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+
+	b.add("("+strings.Join(alternatives, " OR ")+")", args...)
+	return nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
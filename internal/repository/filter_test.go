@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestSubscriptionListWhere(t *testing.T) {
+	userID := uuid.New()
+	prefix := "Yandex_"
+	minPrice, maxPrice := 100, 500
+	activeOn := month(time.March, 2025)
+
+	where := subscriptionListWhere(&models.SubscriptionFilter{
+		UserID:            &userID,
+		ServiceNamePrefix: &prefix,
+		MinPrice:          &minPrice,
+		MaxPrice:          &maxPrice,
+		ActiveOn:          &activeOn,
+		OpenEnded:         true,
+	})
+
+	wantSQL := "user_id = $1 AND service_name LIKE $2 || '%' AND price >= $3 AND price <= $4" +
+		" AND start_date < $5::date + interval '1 month' AND (end_date IS NULL OR end_date >= $6)" +
+		" AND end_date IS NULL"
+	if got := where.and(); got != wantSQL {
+		t.Errorf("and() = %q, want %q", got, wantSQL)
+	}
+
+	wantArgs := []any{userID, `Yandex\_`, 100, 500, activeOn, activeOn}
+	if !reflect.DeepEqual(where.args, wantArgs) {
+		t.Errorf("args = %v, want %v", where.args, wantArgs)
+	}
+}
+
+func TestWhereBuilderKeepsInitialArgs(t *testing.T) {
+	serviceName := "Netflix"
+	where := newWhereBuilder("start", "end")
+	where.addCostFilters(&models.ListSubscriptionsParams{ServiceName: &serviceName})
+
+	if got, want := where.and(), "service_name = $3"; got != want {
+		t.Errorf("and() = %q, want %q", got, want)
+	}
+	if got, want := where.arg(10), "$4"; got != want {
+		t.Errorf("arg() = %q, want %q", got, want)
+	}
+	if got := newWhereBuilder().and(); got != "TRUE" {
+		t.Errorf("and() without conditions = %q, want TRUE", got)
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    []models.SortField
+		want    string
+		wantErr bool
+	}{
+		{"default", nil, "ORDER BY id ASC", false},
+		{
+			name: "id appended as tie breaker",
+			sort: []models.SortField{{Field: "price"}, {Field: "start_date", Desc: true}},
+			want: "ORDER BY price ASC, start_date DESC, id ASC",
+		},
+		{
+			name: "explicit id ends ordering",
+			sort: []models.SortField{{Field: "id", Desc: true}, {Field: "price"}},
+			want: "ORDER BY id DESC",
+		},
+		{"unknown field", []models.SortField{{Field: "price; DROP TABLE subscriptions"}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := orderBy(tt.sort)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("orderBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("orderBy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAfter(t *testing.T) {
+	where := newWhereBuilder()
+	err := where.addAfter(&models.Cursor{
+		Sort:   []models.SortField{{Field: "end_date", Desc: true}, {Field: "id"}},
+		Values: []string{"infinity", "42"},
+	})
+	if err != nil {
+		t.Fatalf("addAfter() error = %v", err)
+	}
+
+	want := "((COALESCE(end_date, 'infinity'::date) < $1::date) OR " +
+		"(COALESCE(end_date, 'infinity'::date) = $2::date AND id > $3::int))"
+	if got := where.and(); got != want {
+		t.Errorf("and() = %q, want %q", got, want)
+	}
+	if len(where.args) != 3 || where.args[0] != "infinity" || where.args[2] != "42" {
+		t.Errorf("args = %v, want [infinity infinity 42]", where.args)
+	}
+
+	if err := newWhereBuilder().addAfter(&models.Cursor{Sort: []models.SortField{{Field: "id"}}}); err == nil {
+		t.Error("addAfter() with mismatched values: expected error")
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// ReserveIdempotencyKey записывает новый ключ идемпотентности без ответа. Ключ,
+// истёкший к моменту now, или ключ того же запроса, чья блокировка истекла
+// (обработчик упал, не записав ответ), перезаписывается. Иначе возвращается
+// сохранённый ключ, а новый не записывается.
+func (r *SubsRepo) ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey, now time.Time) (*models.IdempotencyKey, error) {
+	query := `
+        INSERT INTO idempotency_keys (client, key, request_hash, locked_until, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (client, key) DO UPDATE
+        SET request_hash = EXCLUDED.request_hash,
+            status_code = 0,
+            response_header = NULL,
+            response_body = NULL,
+            locked_until = EXCLUDED.locked_until,
+            expires_at = EXCLUDED.expires_at
+        WHERE idempotency_keys.expires_at <= $6
+            OR (idempotency_keys.status_code = 0
+                AND idempotency_keys.locked_until <= $6
+                AND idempotency_keys.request_hash = EXCLUDED.request_hash)
+    `
+
+	// Сохранённый ключ могут удалить между INSERT и SELECT, тогда резервирование повторяется.
+	for range 2 {
+		res, err := r.db.ExecContext(ctx, query, key.Client, key.Key, key.RequestHash, key.LockedUntil, key.ExpiresAt, now)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка записи ключа идемпотентности: %w", err)
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("не удалось записать ключ идемпотентности: %w", err)
+		}
+		if rows > 0 {
+			return nil, nil
+		}
+
+		var existing models.IdempotencyKey
+		err = r.db.GetContext(ctx, &existing, `SELECT * FROM idempotency_keys WHERE client = $1 AND key = $2`, key.Client, key.Key)
+		if err == nil {
+			return &existing, nil
+		}
+		if !isNoRows(err) {
+			return nil, fmt.Errorf("ошибка получения ключа идемпотентности: %w", err)
+		}
+		logger.FromContext(ctx).Debug("ключ идемпотентности удалён во время резервирования, повтор", "key", key.Key)
+	}
+	return nil, fmt.Errorf("не удалось зарезервировать ключ идемпотентности %q", key.Key)
+}
+
+// SaveIdempotentResponse сохраняет ответ на запрос с зарезервированным ключом.
+func (r *SubsRepo) SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey) error {
+	query := `
+        UPDATE idempotency_keys
+        SET status_code = $3, response_header = $4, response_body = $5
+        WHERE client = $1 AND key = $2
+    `
+
+	if _, err := r.db.ExecContext(ctx, query, key.Client, key.Key, key.StatusCode, string(key.ResponseHeader), key.ResponseBody); err != nil {
+		return fmt.Errorf("ошибка сохранения ответа для ключа идемпотентности: %w", err)
+	}
+	return nil
+}
+
+func (r *SubsRepo) DeleteIdempotencyKey(ctx context.Context, client, key string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE client = $1 AND key = $2`, client, key); err != nil {
+		return fmt.Errorf("ошибка удаления ключа идемпотентности: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys удаляет ключи, истёкшие к моменту now, и возвращает их число.
+func (r *SubsRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления истёкших ключей идемпотентности: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("не удалось удалить истёкшие ключи идемпотентности: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// AddPause добавляет паузу подписки, если она лежит внутри периода подписки и не
+// пересекается с уже записанными. Подписка блокируется до конца транзакции, чтобы
+// параллельное изменение её дат не обошло проверку.
+func (r *SubsRepo) AddPause(ctx context.Context, pause *models.SubscriptionPause) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var sub models.Subscription
+		if err := tx.GetContext(ctx, &sub, `SELECT * FROM subscriptions WHERE id = $1 FOR UPDATE`, pause.SubscriptionID); err != nil {
+			if isNoRows(err) {
+				return fmt.Errorf("%w: не удалось получить подписку id %d", models.ErrSubscriptionNotFound, pause.SubscriptionID)
+			}
+			return fmt.Errorf("ошибка блокировки подписки: %w", err)
+		}
+
+		outside := pause.StartMonth.Before(monthStart(sub.StartDate)) ||
+			(sub.EndDate != nil && (pause.EndMonth == nil || pause.EndMonth.After(monthStart(*sub.EndDate))))
+		if outside {
+			return fmt.Errorf("%w: подписка id %d, месяц %s", models.ErrPauseOutsidePeriod, sub.ID, pause.StartMonth.Format("01-2006"))
+		}
+
+		return insertPause(ctx, tx, pause)
+	})
+}
+
+func (r *SubsRepo) ListPauses(ctx context.Context, subIDs []int) ([]models.SubscriptionPause, error) {
+	query := `
+        SELECT * FROM subscription_pauses
+        WHERE subscription_id = ANY($1)
+        ORDER BY subscription_id, start_month
+    `
+
+	var pauses []models.SubscriptionPause
+	if err := r.db.SelectContext(ctx, &pauses, query, pq.Array(subIDs)); err != nil {
+		return nil, fmt.Errorf("ошибка получения пауз подписок: %w", err)
+	}
+	return pauses, nil
+}
+
+// DeletePause удаляет завершённую паузу подписки. Текущая пауза снимается
+// только возобновлением подписки.
+func (r *SubsRepo) DeletePause(ctx context.Context, subID, pauseID int) error {
+	query := `
+        DELETE FROM subscription_pauses
+        WHERE id = $1 AND subscription_id = $2
+        RETURNING end_month IS NULL
+    `
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var inProgress bool
+		if err := tx.QueryRowxContext(ctx, query, pauseID, subID).Scan(&inProgress); err != nil {
+			if isNoRows(err) {
+				return fmt.Errorf("%w: пауза id %d подписки id %d", models.ErrPauseNotFound, pauseID, subID)
+			}
+			return fmt.Errorf("ошибка удаления паузы подписки: %w", dbError(err))
+		}
+		if inProgress {
+			return fmt.Errorf("%w: пауза id %d подписки id %d", models.ErrPauseInProgress, pauseID, subID)
+		}
+		return nil
+	})
+}
+
+// insertPause проверяет, что пауза не пересекается с другими паузами, и
+// записывает её. Строка подписки к этому моменту уже заблокирована в tx.
+func insertPause(ctx context.Context, tx *sqlx.Tx, pause *models.SubscriptionPause) error {
+	query := `
+        SELECT EXISTS (
+            SELECT 1 FROM subscription_pauses
+            WHERE subscription_id = $1
+                AND start_month <= COALESCE($3::date, 'infinity'::date)
+                AND (end_month IS NULL OR end_month >= $2)
+        )
+    `
+	var overlaps bool
+	if err := tx.GetContext(ctx, &overlaps, query, pause.SubscriptionID, pause.StartMonth, pause.EndMonth); err != nil {
+		return fmt.Errorf("ошибка проверки пауз подписки: %w", err)
+	}
+	if overlaps {
+		return fmt.Errorf("%w: подписка id %d, месяц %s", models.ErrPauseOverlap, pause.SubscriptionID, pause.StartMonth.Format("01-2006"))
+	}
+
+	query = `
+        INSERT INTO subscription_pauses (subscription_id, start_month, end_month)
+        VALUES ($1, $2, $3)
+        RETURNING id
+    `
+	if err := tx.QueryRowxContext(ctx, query, pause.SubscriptionID, pause.StartMonth, pause.EndMonth).Scan(&pause.ID); err != nil {
+		return fmt.Errorf("не удалось записать паузу подписки: %w", dbError(err))
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/lib/pq"
+)
+
+// AddPrice записывает изменение цены. Цена на тот же месяц заменяется, в этом
+// случае replaced = true. Строка подписки блокируется на время транзакции, чтобы
+// параллельные записи цены одной подписки не разошлись в определении замены.
+func (r *SubsRepo) AddPrice(ctx context.Context, price *models.SubscriptionPrice) (replaced bool, err error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	var subID int
+	query := `SELECT id FROM subscriptions WHERE id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &subID, query, price.SubscriptionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("%w: изменение цены подписки id %d", models.ErrSubscriptionNotFound, price.SubscriptionID)
+		}
+		return false, fmt.Errorf("не удалось заблокировать подписку: %w", err)
+	}
+
+	var existing []int
+	query = `SELECT id FROM subscription_prices WHERE subscription_id = $1 AND effective_from = $2 FOR UPDATE`
+	if err := tx.SelectContext(ctx, &existing, query, price.SubscriptionID, price.EffectiveFrom); err != nil {
+		return false, fmt.Errorf("не удалось проверить цену на месяц: %w", err)
+	}
+	replaced = len(existing) > 0
+
+	query = `
+        INSERT INTO subscription_prices (subscription_id, price, effective_from)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+        RETURNING id
+    `
+	err = tx.QueryRowContext(ctx, query, price.SubscriptionID, price.Price, price.EffectiveFrom).Scan(&price.ID)
+	if err != nil {
+		return false, fmt.Errorf("не удалось записать изменение цены подписки: %w", dbError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("не удалось зафиксировать изменение цены: %w", err)
+	}
+	return replaced, nil
+}
+
+func (r *SubsRepo) ListPrices(ctx context.Context, subIDs []int) ([]models.SubscriptionPrice, error) {
+	query := `
+        SELECT * FROM subscription_prices
+        WHERE subscription_id = ANY($1)
+        ORDER BY subscription_id, effective_from
+    `
+
+	var prices []models.SubscriptionPrice
+	if err := r.db.SelectContext(ctx, &prices, query, pq.Array(subIDs)); err != nil {
+		return nil, fmt.Errorf("ошибка получения истории цен: %w", err)
+	}
+	return prices, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Pause приостанавливает действующую подписку со следующего месяца после today.
+func (r *SubsRepo) Pause(ctx context.Context, id int, today time.Time) (*models.Subscription, error) {
+	var sub *models.Subscription
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := transition(ctx, tx, id, today, models.StatusPaused, "", models.StatusActive); err != nil {
+			return err
+		}
+
+		pause := models.SubscriptionPause{SubscriptionID: id, StartMonth: nextMonth(today)}
+		if err := insertPause(ctx, tx, &pause); err != nil {
+			return err
+		}
+
+		var err error
+		sub, err = getByID(ctx, tx, id)
+		return err
+	})
+	return sub, err
+}
+
+// Resume возобновляет приостановленную подписку с месяца today.
+func (r *SubsRepo) Resume(ctx context.Context, id int, today time.Time) (*models.Subscription, error) {
+	var sub *models.Subscription
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := transition(ctx, tx, id, today, models.StatusActive, "", models.StatusPaused); err != nil {
+			return err
+		}
+		if err := closePause(ctx, tx, id, monthStart(today).AddDate(0, -1, 0)); err != nil {
+			return err
+		}
+
+		var err error
+		sub, err = getByID(ctx, tx, id)
+		return err
+	})
+	return sub, err
+}
+
+// Cancel отменяет действующую или приостановленную подписку: end_date переносится
+// на today (на месяц today для подписок с точностью до месяца), если она была позже.
+func (r *SubsRepo) Cancel(ctx context.Context, id int, today time.Time) (*models.Subscription, error) {
+	var sub *models.Subscription
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		endDate := `end_date = LEAST(COALESCE(end_date, 'infinity'::date),
+			CASE WHEN date_precision = 'day' THEN $4::date ELSE date_trunc('month', $4::date)::date END)`
+		if err := transition(ctx, tx, id, today, models.StatusCancelled, endDate, models.StatusActive, models.StatusPaused); err != nil {
+			return err
+		}
+		if err := closePause(ctx, tx, id, monthStart(today)); err != nil {
+			return err
+		}
+
+		var err error
+		sub, err = getByID(ctx, tx, id)
+		return err
+	})
+	return sub, err
+}
+
+// transition переводит подписку в статус to, если её текущий статус входит в from
+// и она ещё не истекла к дню today. set — дополнительные присваивания в UPDATE,
+// в них доступен параметр $4 (день today).
+func transition(ctx context.Context, tx *sqlx.Tx, id int, today time.Time, to models.SubscriptionStatus, set string, from ...models.SubscriptionStatus) error {
+	if set != "" {
+		set = ", " + set
+	}
+	query := `
+        UPDATE subscriptions
+        SET status = $2, version = version + 1` + set + `
+        WHERE id = $1 AND status = ANY($3)
+            AND (end_date IS NULL OR end_date >= CASE
+                WHEN date_precision = 'day' THEN $4::date
+                ELSE date_trunc('month', $4::date)::date
+            END)
+    `
+
+	statuses := make([]string, len(from))
+	for i, status := range from {
+		statuses[i] = string(status)
+	}
+
+	res, err := tx.ExecContext(ctx, query, id, to, pq.Array(statuses), today)
+	if err != nil {
+		return fmt.Errorf("ошибка смены статуса подписки: %w", dbError(err))
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("не удалось сменить статус подписки: %w", err)
+	}
+	if rows > 0 {
+		return nil
+	}
+
+	sub, err := getByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: подписка id %d, %s -> %s", models.ErrInvalidTransition, id, sub.StatusAt(today), to)
+}
+
+// closePause завершает текущую паузу подписки месяцем month. Пауза, которая
+// начинается позже month, так и не началась и удаляется: завершить её месяцем
+// month нельзя, конец паузы был бы раньше начала.
+func closePause(ctx context.Context, tx *sqlx.Tx, id int, month time.Time) error {
+	query := `DELETE FROM subscription_pauses WHERE subscription_id = $1 AND end_month IS NULL AND start_month > $2`
+	if _, err := tx.ExecContext(ctx, query, id, month); err != nil {
+		return fmt.Errorf("не удалось удалить пустую паузу подписки: %w", dbError(err))
+	}
+
+	query = `UPDATE subscription_pauses SET end_month = $2 WHERE subscription_id = $1 AND end_month IS NULL`
+	if _, err := tx.ExecContext(ctx, query, id, month); err != nil {
+		return fmt.Errorf("не удалось завершить паузу подписки: %w", dbError(err))
+	}
+	return nil
+}
+
+func getByID(ctx context.Context, q sqlx.QueryerContext, id int) (*models.Subscription, error) {
+	var sub models.Subscription
+	if err := sqlx.GetContext(ctx, q, &sub, `SELECT * FROM subscriptions WHERE id=$1`, id); err != nil {
+		if isNoRows(err) {
+			return nil, fmt.Errorf("%w: не удалось получить подписку id %d", models.ErrSubscriptionNotFound, id)
+		}
+		return nil, fmt.Errorf("ошибка получения подписки: %w", err)
+	}
+	return &sub, nil
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
+
+// withTx выполняет fn в транзакции: при ошибке изменения откатываются.
+func (r *SubsRepo) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.withTxOptions(ctx, nil, fn)
+}
+
+// withTxOptions выполняет fn в транзакции с уровнем изоляции и режимом из opts.
+func (r *SubsRepo) withTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		logger.FromContext(ctx).Debug("транзакция отменена", "error", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать транзакцию: %w", dbError(err))
+	}
+	return nil
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func nextMonth(t time.Time) time.Time {
+	return monthStart(t).AddDate(0, 1, 0)
+}
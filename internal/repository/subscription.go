@@ -3,7 +3,6 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"strings"
 
@@ -20,78 +19,197 @@ func NewSubsRepo(db *sqlx.DB) *SubsRepo {
 }
 
 func (r *SubsRepo) Create(ctx context.Context, sub *models.Subscription) error {
+	return createSubscription(ctx, r.db, sub)
+}
+
+func createSubscription(ctx context.Context, q sqlx.QueryerContext, sub *models.Subscription) error {
 	query := `
-        INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-        VALUES ($1, $2, $3, $4, $5)
-        RETURNING id
+        INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date, billing_period, date_precision, currency, trial_end_date, status)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        RETURNING id, version
     `
 
-	err := r.db.QueryRowContext(ctx, query, sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate).Scan(&sub.ID)
+	err := q.QueryRowxContext(ctx, query, sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate, sub.BillingPeriod, sub.DatePrecision, sub.Currency, sub.TrialEndDate, sub.Status).Scan(&sub.ID, &sub.Version)
 	if err != nil {
-		return fmt.Errorf("не удалось записать данные подписки: %w", err)
+		return fmt.Errorf("не удалось записать данные подписки: %w", dbError(err))
 	}
 	return nil
 }
 
 func (r *SubsRepo) GetByID(ctx context.Context, id int) (*models.Subscription, error) {
-	query := `SELECT * FROM subscriptions WHERE id=$1`
+	return getByID(ctx, r.db, id)
+}
+
+func (r *SubsRepo) GetAll(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, error) {
+	return selectSubscriptions(ctx, r.db, filter)
+}
+
+// GetAllWithCount возвращает страницу подписок и число всех подписок под фильтрами
+// списка без учёта пагинации и курсора. Оба запроса читают один снимок базы,
+// поэтому total согласован со страницей.
+func (r *SubsRepo) GetAllWithCount(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, int, error) {
+	var subs []models.Subscription
+	var total int
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := r.withTxOptions(ctx, opts, func(tx *sqlx.Tx) error {
+		var err error
+		if subs, err = selectSubscriptions(ctx, tx, filter); err != nil {
+			return err
+		}
 
-	var sub models.Subscription
-	if err := r.db.GetContext(ctx, &sub, query, id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("%w: не удалось получить подписку id %d", models.ErrSubscriptionNotFound, id)
+		where := subscriptionListWhere(filter)
+		query := `SELECT COUNT(*) FROM subscriptions WHERE ` + where.and()
+		if err := tx.GetContext(ctx, &total, query, where.args...); err != nil {
+			return fmt.Errorf("ошибка подсчёта подписок: %w", err)
 		}
-		return nil, fmt.Errorf("ошибка получения подписки: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
-	return &sub, nil
+	return subs, total, nil
 }
 
-func (r *SubsRepo) GetAll(ctx context.Context, limit, offset int) ([]models.Subscription, error) {
+func selectSubscriptions(ctx context.Context, q sqlx.QueryerContext, filter *models.SubscriptionFilter) ([]models.Subscription, error) {
+	order, err := orderBy(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	where := subscriptionListWhere(filter)
+	offset := filter.Offset
+	if filter.After != nil {
+		if err := where.addAfter(filter.After); err != nil {
+			return nil, err
+		}
+		offset = 0
+	}
+
 	query := `
         SELECT * FROM subscriptions
-        ORDER BY id
-        LIMIT $1 OFFSET $2
+        WHERE ` + where.and() + `
+        ` + order + `
+        LIMIT ` + where.arg(filter.Limit) + ` OFFSET ` + where.arg(offset) + `
     `
 
 	var subs []models.Subscription
-	if err := r.db.SelectContext(ctx, &subs, query, limit, offset); err != nil {
+	if err := sqlx.SelectContext(ctx, q, &subs, query, where.args...); err != nil {
 		return nil, fmt.Errorf("ошибка получения подписок: %w", err)
 	}
 	return subs, nil
 }
 
 func (r *SubsRepo) Update(ctx context.Context, sub *models.Subscription) error {
+	return updateSubscription(ctx, r.db, sub)
+}
+
+func updateSubscription(ctx context.Context, q sqlx.ExtContext, sub *models.Subscription) error {
 	query := `
         UPDATE subscriptions
         SET service_name = :service_name,
             price = :price,
             user_id = :user_id,
             start_date = :start_date,
-            end_date = :end_date
-        WHERE id = :id
+            end_date = :end_date,
+            billing_period = :billing_period,
+            date_precision = :date_precision,
+            currency = :currency,
+            trial_end_date = :trial_end_date,
+            status = :status,
+            version = version + 1
+        WHERE id = :id AND ` + versionMatches + `
+        RETURNING version
     `
+	return updateVersioned(ctx, q, query, sub)
+}
 
-	res, err := r.db.NamedExecContext(ctx, query, sub)
+// versionMatches — условие оптимистичной блокировки: версия 0 означает,
+// что проверять версию не нужно.
+const versionMatches = `(:version = 0 OR version = :version)`
+
+// updateVersioned выполняет UPDATE подписки с проверкой версии и записывает
+// в sub новую версию.
+func updateVersioned(ctx context.Context, q sqlx.ExtContext, query string, sub *models.Subscription) error {
+	rows, err := sqlx.NamedQueryContext(ctx, q, query, sub)
 	if err != nil {
-		return fmt.Errorf("ошибка обновления записи: %w", err)
+		return fmt.Errorf("ошибка обновления записи: %w", dbError(err))
 	}
 
-	rows, err := res.RowsAffected()
+	updated := rows.Next()
+	if updated {
+		err = rows.Scan(&sub.Version)
+	}
+	// Строки закрываются до versionConflict: в транзакции нельзя выполнить
+	// следующий запрос, пока не дочитан предыдущий.
+	rows.Close()
+	if err == nil {
+		err = rows.Err()
+	}
 	if err != nil {
-		return fmt.Errorf("не удалось обновить запись: %w", err)
+		return fmt.Errorf("не удалось обновить запись: %w", dbError(err))
 	}
-	if rows == 0 {
-		return fmt.Errorf("%w: обновление данных подписки id %d", models.ErrSubscriptionNotFound, sub.ID)
+	if !updated {
+		return versionConflict(ctx, q, sub.ID, "обновление данных")
 	}
 	return nil
 }
 
-func (r *SubsRepo) Delete(ctx context.Context, id int) error {
-	query := `DELETE FROM subscriptions WHERE id=$1`
+// versionConflict объясняет, почему условный запрос не затронул подписку id:
+// её нет или её версия не совпала с ожидаемой.
+func versionConflict(ctx context.Context, q sqlx.QueryerContext, id int, action string) error {
+	if _, err := getByID(ctx, q, id); err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s подписки id %d", models.ErrVersionMismatch, action, id)
+}
+
+// updatableColumns — колонки, которые можно менять через UpdateColumns.
+var updatableColumns = map[string]bool{
+	"service_name":   true,
+	"price":          true,
+	"user_id":        true,
+	"start_date":     true,
+	"end_date":       true,
+	"billing_period": true,
+	"date_precision": true,
+	"currency":       true,
+	"trial_end_date": true,
+	"status":         true,
+}
+
+// UpdateColumns обновляет у подписки только перечисленные колонки, проверяя версию как Update.
+func (r *SubsRepo) UpdateColumns(ctx context.Context, sub *models.Subscription, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
 
-	res, err := r.db.ExecContext(ctx, query, id)
+	set := make([]string, len(columns))
+	for i, column := range columns {
+		if !updatableColumns[column] {
+			return fmt.Errorf("колонку %s нельзя обновить", column)
+		}
+		set[i] = column + " = :" + column
+	}
+	query := `
+        UPDATE subscriptions
+        SET ` + strings.Join(set, ", ") + `, version = version + 1
+        WHERE id = :id AND ` + versionMatches + `
+        RETURNING version
+    `
+	return updateVersioned(ctx, r.db, query, sub)
+}
+
+// Delete удаляет подписку, если её версия равна version (0 — без проверки версии).
+func (r *SubsRepo) Delete(ctx context.Context, id, version int) error {
+	return deleteSubscription(ctx, r.db, id, version)
+}
+
+func deleteSubscription(ctx context.Context, q sqlx.ExtContext, id, version int) error {
+	query := `DELETE FROM subscriptions WHERE id=$1 AND ($2 = 0 OR version = $2)`
+
+	res, err := q.ExecContext(ctx, query, id, version)
 	if err != nil {
-		return fmt.Errorf("ошибка удаления записи: %w", err)
+		return fmt.Errorf("ошибка удаления записи: %w", dbError(err))
 	}
 
 	rows, err := res.RowsAffected()
@@ -99,40 +217,24 @@ func (r *SubsRepo) Delete(ctx context.Context, id int) error {
 		return fmt.Errorf("не удалось удалить запись: %w", err)
 	}
 	if rows == 0 {
-		return fmt.Errorf("%w: удаление подписки id %d", models.ErrSubscriptionNotFound, id)
+		return versionConflict(ctx, q, id, "удаление")
 	}
 	return nil
 }
 
 func (r *SubsRepo) ListByUserAndService(ctx context.Context, params *models.ListSubscriptionsParams) ([]models.Subscription, error) {
+	where := newWhereBuilder(params.StartDate, params.EndDate)
+	where.addCostFilters(params)
+
 	query := `
 		SELECT * FROM subscriptions
-		WHERE start_date <= $2
+		WHERE start_date < $2::date + interval '1 month'
 			AND (end_date IS NULL OR end_date >= $1)
+			AND ` + where.and() + `;
 	`
 
-	args := []any{params.StartDate, params.EndDate}
-	argIndex := 3
-
-	var conditions []string
-	if params.UserID != nil {
-		conditions = append(conditions, fmt.Sprintf("user_id = $%d", argIndex))
-		args = append(args, *params.UserID)
-		argIndex++
-	}
-	if params.ServiceName != nil {
-		conditions = append(conditions, fmt.Sprintf("service_name = $%d", argIndex))
-		args = append(args, *params.ServiceName)
-		argIndex++
-	}
-
-	if len(conditions) > 0 {
-		query += " AND " + strings.Join(conditions, " AND ")
-	}
-	query += ";"
-
 	var subs []models.Subscription
-	err := r.db.SelectContext(ctx, &subs, query, args...)
+	err := r.db.SelectContext(ctx, &subs, query, where.args...)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения подписок: %w", err)
 	}
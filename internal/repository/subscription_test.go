@@ -0,0 +1,713 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/billing"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// Тесты репозитория работают с настоящей PostgreSQL с применёнными миграциями.
+// DSN передаётся через переменную окружения TEST_DB_DSN, без неё тесты пропускаются.
+func newTestRepo(t *testing.T) *SubsRepo {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN не задан")
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("не удалось подключиться к базе данных: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewSubsRepo(db)
+}
+
+func month(m time.Month, year int) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthPtr(m time.Month, year int) *time.Time {
+	t := month(m, year)
+	return &t
+}
+
+func day(d int, m time.Month, year int) time.Time {
+	return time.Date(year, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func dayPtr(d int, m time.Month, year int) *time.Time {
+	t := day(d, m, year)
+	return &t
+}
+
+func createTestSubscriptions(t *testing.T, repo *SubsRepo, userID uuid.UUID, subs []models.Subscription) {
+	t.Helper()
+
+	ctx := context.Background()
+	for i := range subs {
+		subs[i].UserID = userID
+		if err := repo.Create(ctx, &subs[i]); err != nil {
+			t.Fatalf("не удалось создать подписку: %v", err)
+		}
+	}
+
+	t.Cleanup(func() {
+		repo.db.ExecContext(context.Background(), `DELETE FROM subscriptions WHERE user_id = $1`, userID)
+	})
+}
+
+// listWithDetails загружает подписки вместе с данными, нужными эталонному расчёту.
+func listWithDetails(t *testing.T, repo *SubsRepo, params *models.ListSubscriptionsParams) []models.Subscription {
+	t.Helper()
+
+	ctx := context.Background()
+	subs, err := repo.ListByUserAndService(ctx, params)
+	if err != nil {
+		t.Fatalf("ListByUserAndService() error: %v", err)
+	}
+
+	for i := range subs {
+		subs[i].Prices, err = repo.ListPrices(ctx, []int{subs[i].ID})
+		if err != nil {
+			t.Fatalf("ListPrices() error: %v", err)
+		}
+		subs[i].Discounts, err = repo.ListDiscounts(ctx, []int{subs[i].ID})
+		if err != nil {
+			t.Fatalf("ListDiscounts() error: %v", err)
+		}
+		subs[i].Pauses, err = repo.ListPauses(ctx, []int{subs[i].ID})
+		if err != nil {
+			t.Fatalf("ListPauses() error: %v", err)
+		}
+	}
+	return subs
+}
+
+// testCurrency — код ISO 4217, зарезервированный для тестов, чтобы не задеть настоящие курсы.
+const testCurrency = "XTS"
+
+func TestTotalCostMatchesReference(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	subs := []models.Subscription{
+		{ServiceName: "Netflix", Price: 542, StartDate: month(time.November, 2024), EndDate: monthPtr(time.February, 2025)},
+		{ServiceName: "Netflix", Price: 799, StartDate: month(time.June, 2023)},
+		{ServiceName: "Yandex Plus", Price: 400, StartDate: month(time.January, 2025), EndDate: monthPtr(time.January, 2025)},
+		{ServiceName: "Spotify", Price: 169, StartDate: month(time.March, 2022), EndDate: monthPtr(time.March, 2026)},
+		{ServiceName: "Kinopoisk", Price: 1001, StartDate: month(time.November, 2024), BillingPeriod: models.BillingQuarterly},
+		{ServiceName: "Kinopoisk", Price: 2990, StartDate: month(time.April, 2023), EndDate: monthPtr(time.June, 2025), BillingPeriod: models.BillingYearly},
+		{ServiceName: "Gym", Price: 150, StartDate: month(time.December, 2024), EndDate: monthPtr(time.February, 2025), BillingPeriod: models.BillingWeekly},
+		{ServiceName: "Gym", Price: 150, StartDate: day(19, time.December, 2024), EndDate: dayPtr(11, time.February, 2025), BillingPeriod: models.BillingWeekly, DatePrecision: models.PrecisionDay},
+		{ServiceName: "Netflix", Price: 620, StartDate: day(25, time.January, 2025), EndDate: dayPtr(20, time.July, 2025), DatePrecision: models.PrecisionDay},
+		{ServiceName: "Kinopoisk", Price: 1500, StartDate: day(31, time.October, 2024), BillingPeriod: models.BillingQuarterly, DatePrecision: models.PrecisionDay},
+		{ServiceName: "Okko", Price: 399, StartDate: month(time.November, 2024), TrialEndDate: monthPtr(time.January, 2025)},
+		{ServiceName: "Okko", Price: 310, StartDate: day(10, time.January, 2025), TrialEndDate: dayPtr(9, time.February, 2025), DatePrecision: models.PrecisionDay},
+		{ServiceName: "ChatGPT", Price: 20, StartDate: month(time.December, 2024), EndDate: monthPtr(time.March, 2025), Currency: testCurrency},
+	}
+	for i := range subs {
+		if subs[i].BillingPeriod == "" {
+			subs[i].BillingPeriod = models.BillingMonthly
+		}
+		if subs[i].DatePrecision == "" {
+			subs[i].DatePrecision = models.PrecisionMonth
+		}
+		if subs[i].Currency == "" {
+			subs[i].Currency = models.DefaultCurrency
+		}
+		subs[i].Status = models.StatusActive
+	}
+	createTestSubscriptions(t, repo, userID, subs)
+
+	for _, price := range []models.SubscriptionPrice{
+		{SubscriptionID: subs[0].ID, Price: 599, EffectiveFrom: month(time.January, 2025)},
+		{SubscriptionID: subs[1].ID, Price: 899, EffectiveFrom: month(time.February, 2025)},
+		{SubscriptionID: subs[1].ID, Price: 999, EffectiveFrom: month(time.January, 2026)},
+	} {
+		if replaced, err := repo.AddPrice(ctx, &price); err != nil || replaced {
+			t.Fatalf("AddPrice() = %v, %v", replaced, err)
+		}
+	}
+
+	// Повторная запись на тот же месяц заменяет цену, а не добавляет вторую.
+	samePrice := models.SubscriptionPrice{SubscriptionID: subs[0].ID, Price: 599, EffectiveFrom: month(time.January, 2025)}
+	if replaced, err := repo.AddPrice(ctx, &samePrice); err != nil || !replaced {
+		t.Fatalf("AddPrice() на тот же месяц = %v, %v, want replaced", replaced, err)
+	}
+
+	for _, discount := range []models.SubscriptionDiscount{
+		{SubscriptionID: subs[0].ID, Kind: models.DiscountPercent, Value: 50, StartMonth: month(time.November, 2024), EndMonth: monthPtr(time.December, 2024)},
+		{SubscriptionID: subs[1].ID, Kind: models.DiscountFixed, Value: 100, StartMonth: month(time.January, 2025)},
+		{SubscriptionID: subs[1].ID, Kind: models.DiscountPercent, Value: 15, StartMonth: month(time.February, 2025), EndMonth: monthPtr(time.March, 2025)},
+		{SubscriptionID: subs[len(subs)-1].ID, Kind: models.DiscountPercent, Value: 25, StartMonth: month(time.January, 2025), EndMonth: monthPtr(time.February, 2025)},
+	} {
+		if err := repo.AddDiscount(ctx, &discount); err != nil {
+			t.Fatalf("AddDiscount() error: %v", err)
+		}
+	}
+
+	// Netflix приостановлен на апрель-май 2025, Spotify — с февраля 2025 без возобновления.
+	if _, err := repo.Pause(ctx, subs[1].ID, day(15, time.March, 2025)); err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+	if _, err := repo.Resume(ctx, subs[1].ID, day(10, time.June, 2025)); err != nil {
+		t.Fatalf("Resume() error: %v", err)
+	}
+	if _, err := repo.Pause(ctx, subs[3].ID, day(20, time.January, 2025)); err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+	if err := repo.AddPause(ctx, &models.SubscriptionPause{SubscriptionID: subs[8].ID, StartMonth: month(time.March, 2025), EndMonth: monthPtr(time.April, 2025)}); err != nil {
+		t.Fatalf("AddPause() error: %v", err)
+	}
+
+	// Курса на декабрь 2024 нет, чтобы проверить список недостающих курсов.
+	for _, rate := range []models.ExchangeRate{
+		{Currency: testCurrency, Month: month(time.January, 2025), Rate: 101.25},
+		{Currency: testCurrency, Month: month(time.February, 2025), Rate: 97.4},
+		{Currency: testCurrency, Month: month(time.March, 2025), Rate: 88.1},
+	} {
+		if err := repo.SaveExchangeRate(ctx, &rate); err != nil {
+			t.Fatalf("SaveExchangeRate() error: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		repo.db.ExecContext(context.Background(), `DELETE FROM exchange_rates WHERE currency = $1`, testCurrency)
+	})
+
+	windows := []billing.Period{
+		{Start: month(time.July, 2025), End: month(time.September, 2025)},
+		{Start: month(time.December, 2024), End: month(time.March, 2025)},
+		{Start: month(time.January, 2025), End: month(time.March, 2025)},
+		{Start: month(time.January, 2025), End: month(time.January, 2025)},
+		{Start: month(time.January, 2020), End: month(time.December, 2027)},
+		{Start: month(time.January, 2019), End: month(time.December, 2019)},
+	}
+
+	for _, window := range windows {
+		for _, opts := range []models.CostOptions{
+			{Proration: models.ProrationFull},
+			{Proration: models.ProrationDaily},
+			{Proration: models.ProrationRenewal},
+			{Normalize: true, Proration: models.ProrationFull},
+			{Normalize: true, Proration: models.ProrationDaily},
+			{Proration: models.ProrationFull, Currency: testCurrency},
+		} {
+			name := fmt.Sprintf("%s_%s_%+v", window.Start.Format("01-2006"), window.End.Format("01-2006"), opts)
+			t.Run(name, func(t *testing.T) {
+				params := &models.ListSubscriptionsParams{StartDate: window.Start, EndDate: window.End, UserID: &userID, Options: opts}
+
+				subs := listWithDetails(t, repo, params)
+				rates, err := repo.ListExchangeRates(ctx, &models.ExchangeRatesFilter{From: window.Start, To: window.End})
+				if err != nil {
+					t.Fatalf("ListExchangeRates() error: %v", err)
+				}
+				want, wantErr := billing.TotalCost(subs, window, opts, rates)
+
+				got, err := repo.TotalCost(ctx, params)
+				if !sameMissingRates(err, wantErr) {
+					t.Fatalf("TotalCost() error = %v, reference error = %v", err, wantErr)
+				}
+				if got != want {
+					t.Errorf("TotalCost() = %d, reference = %d", got, want)
+				}
+
+				groups, err := repo.TotalCostGroupedBy(ctx, params, models.GroupByServiceName)
+				if !sameMissingRates(err, wantErr) {
+					t.Fatalf("TotalCostGroupedBy() error = %v, reference error = %v", err, wantErr)
+				}
+				groupedTotal := 0
+				for _, group := range groups {
+					groupedTotal += group.Cost
+				}
+				if groupedTotal != want {
+					t.Errorf("sum of TotalCostGroupedBy() = %d, reference = %d", groupedTotal, want)
+				}
+			})
+		}
+	}
+}
+
+func sameMissingRates(got, want error) bool {
+	if got == nil || want == nil {
+		return got == nil && want == nil
+	}
+
+	var gotMissing, wantMissing *models.MissingRatesError
+	if !errors.As(got, &gotMissing) || !errors.As(want, &wantMissing) {
+		return false
+	}
+	return reflect.DeepEqual(gotMissing.Missing, wantMissing.Missing)
+}
+
+func TestStatusTransitions(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	subs := []models.Subscription{
+		{ServiceName: "Netflix", Price: 542, StartDate: month(time.January, 2025), BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth, Currency: models.DefaultCurrency, Status: models.StatusActive},
+		{ServiceName: "Spotify", Price: 169, StartDate: month(time.January, 2025), EndDate: monthPtr(time.March, 2025), BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth, Currency: models.DefaultCurrency, Status: models.StatusActive},
+	}
+	createTestSubscriptions(t, repo, uuid.New(), subs)
+	id := subs[0].ID
+
+	steps := []struct {
+		name    string
+		change  func(ctx context.Context, id int, today time.Time) (*models.Subscription, error)
+		today   time.Time
+		want    models.SubscriptionStatus
+		wantErr error
+	}{
+		{"pause", repo.Pause, day(10, time.February, 2025), models.StatusPaused, nil},
+		{"pause paused", repo.Pause, day(11, time.February, 2025), "", models.ErrInvalidTransition},
+		{"resume", repo.Resume, day(5, time.May, 2025), models.StatusActive, nil},
+		// Пауза с июня снята в том же месяце, так и не начавшись.
+		{"pause again", repo.Pause, day(6, time.May, 2025), models.StatusPaused, nil},
+		{"resume same month", repo.Resume, day(20, time.May, 2025), models.StatusActive, nil},
+		// Пауза с июля отменена вместе с подпиской в июне.
+		{"pause before cancel", repo.Pause, day(1, time.June, 2025), models.StatusPaused, nil},
+		{"cancel same month", repo.Cancel, day(20, time.June, 2025), models.StatusCancelled, nil},
+		{"resume cancelled", repo.Resume, day(21, time.June, 2025), "", models.ErrInvalidTransition},
+	}
+	for _, step := range steps {
+		sub, err := step.change(ctx, id, step.today)
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: error = %v, want %v", step.name, err, step.wantErr)
+		}
+		if err == nil && sub.Status != step.want {
+			t.Errorf("%s: status = %s, want %s", step.name, sub.Status, step.want)
+		}
+	}
+
+	sub, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if sub.EndDate == nil || !sub.EndDate.Equal(month(time.June, 2025)) {
+		t.Errorf("end_date after cancel = %v, want 06-2025", sub.EndDate)
+	}
+
+	pauses, err := repo.ListPauses(ctx, []int{id})
+	if err != nil {
+		t.Fatalf("ListPauses() error: %v", err)
+	}
+	if len(pauses) != 1 || !pauses[0].StartMonth.Equal(month(time.March, 2025)) || pauses[0].EndMonth == nil || !pauses[0].EndMonth.Equal(month(time.April, 2025)) {
+		t.Errorf("pauses = %+v, want 03-2025..04-2025", pauses)
+	}
+
+	if _, err := repo.Pause(ctx, subs[1].ID, day(1, time.April, 2025)); !errors.Is(err, models.ErrInvalidTransition) {
+		t.Errorf("pause expired subscription: error = %v, want %v", err, models.ErrInvalidTransition)
+	}
+}
+
+func TestPauseIntervals(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	subs := []models.Subscription{
+		{ServiceName: "Netflix", Price: 542, StartDate: month(time.January, 2025), BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth, Currency: models.DefaultCurrency, Status: models.StatusActive},
+	}
+	createTestSubscriptions(t, repo, uuid.New(), subs)
+	id := subs[0].ID
+
+	first := models.SubscriptionPause{SubscriptionID: id, StartMonth: month(time.March, 2025), EndMonth: monthPtr(time.April, 2025)}
+	if err := repo.AddPause(ctx, &first); err != nil {
+		t.Fatalf("AddPause() error: %v", err)
+	}
+
+	for _, pause := range []models.SubscriptionPause{
+		{SubscriptionID: id, StartMonth: month(time.April, 2025), EndMonth: monthPtr(time.May, 2025)},
+		{SubscriptionID: id, StartMonth: month(time.January, 2025), EndMonth: monthPtr(time.March, 2025)},
+		{SubscriptionID: id, StartMonth: month(time.February, 2025)},
+	} {
+		if err := repo.AddPause(ctx, &pause); !errors.Is(err, models.ErrPauseOverlap) {
+			t.Errorf("AddPause(%s) error = %v, want %v", pause.StartMonth.Format("01-2006"), err, models.ErrPauseOverlap)
+		}
+	}
+
+	before := models.SubscriptionPause{SubscriptionID: id, StartMonth: month(time.December, 2024), EndMonth: monthPtr(time.December, 2024)}
+	if err := repo.AddPause(ctx, &before); !errors.Is(err, models.ErrPauseOutsidePeriod) {
+		t.Errorf("AddPause(before start) error = %v, want %v", err, models.ErrPauseOutsidePeriod)
+	}
+
+	// Пауза по статусу начинается с июня и не пересекается с мартом-апрелем.
+	if _, err := repo.Pause(ctx, id, day(15, time.May, 2025)); err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+	pauses, err := repo.ListPauses(ctx, []int{id})
+	if err != nil {
+		t.Fatalf("ListPauses() error: %v", err)
+	}
+	if len(pauses) != 2 {
+		t.Fatalf("ListPauses() returned %d pauses, want 2", len(pauses))
+	}
+
+	if err := repo.DeletePause(ctx, id, pauses[1].ID); !errors.Is(err, models.ErrPauseInProgress) {
+		t.Errorf("DeletePause(in progress) error = %v, want %v", err, models.ErrPauseInProgress)
+	}
+	if err := repo.DeletePause(ctx, id, first.ID); err != nil {
+		t.Errorf("DeletePause() error: %v", err)
+	}
+	if err := repo.DeletePause(ctx, id, first.ID); !errors.Is(err, models.ErrPauseNotFound) {
+		t.Errorf("DeletePause(deleted) error = %v, want %v", err, models.ErrPauseNotFound)
+	}
+}
+
+func TestGetAllFilters(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	subs := []models.Subscription{
+		{ServiceName: "Yandex Plus", Price: 400, StartDate: month(time.January, 2025)},
+		{ServiceName: "Yandex Music", Price: 300, StartDate: month(time.March, 2025), EndDate: monthPtr(time.June, 2025)},
+		{ServiceName: "Netflix", Price: 800, StartDate: month(time.January, 2024), EndDate: monthPtr(time.December, 2024)},
+	}
+	for i := range subs {
+		subs[i].BillingPeriod, subs[i].DatePrecision = models.BillingMonthly, models.PrecisionMonth
+		subs[i].Currency, subs[i].Status = models.DefaultCurrency, models.StatusActive
+	}
+	createTestSubscriptions(t, repo, userID, subs)
+
+	prefix, maxPrice := "Yandex", 350
+	activeOn := month(time.February, 2025)
+	tests := []struct {
+		name   string
+		filter models.SubscriptionFilter
+		want   []int
+	}{
+		{"sorted by price desc", models.SubscriptionFilter{Sort: []models.SortField{{Field: "price", Desc: true}}}, []int{subs[2].ID, subs[0].ID, subs[1].ID}},
+		{"prefix and price", models.SubscriptionFilter{ServiceNamePrefix: &prefix, MaxPrice: &maxPrice}, []int{subs[1].ID}},
+		{"active on", models.SubscriptionFilter{ActiveOn: &activeOn}, []int{subs[0].ID}},
+		{"open-ended", models.SubscriptionFilter{OpenEnded: true}, []int{subs[0].ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.UserID, tt.filter.Limit = &userID, 10
+
+			got, err := repo.GetAll(ctx, &tt.filter)
+			if err != nil {
+				t.Fatalf("GetAll() error: %v", err)
+			}
+			ids := make([]int, len(got))
+			for i, sub := range got {
+				ids[i] = sub.ID
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("GetAll() ids = %v, want %v", ids, tt.want)
+			}
+
+			tt.filter.Limit, tt.filter.Offset = 1, 1
+			page, total, err := repo.GetAllWithCount(ctx, &tt.filter)
+			if err != nil {
+				t.Fatalf("GetAllWithCount() error: %v", err)
+			}
+			if total != len(tt.want) {
+				t.Errorf("GetAllWithCount() total = %d, want %d", total, len(tt.want))
+			}
+			if want := min(1, max(len(tt.want)-1, 0)); len(page) != want {
+				t.Errorf("GetAllWithCount() returned %d subscriptions, want %d", len(page), want)
+			}
+		})
+	}
+	t.Run("keyset pages", func(t *testing.T) {
+		filter := models.SubscriptionFilter{UserID: &userID, Limit: 1, Sort: []models.SortField{{Field: "end_date", Desc: true}}}
+
+		var ids []int
+		for range subs {
+			page, err := repo.GetAll(ctx, &filter)
+			if err != nil {
+				t.Fatalf("GetAll() error: %v", err)
+			}
+			if len(page) != 1 {
+				t.Fatalf("GetAll() returned %d subscriptions, want 1", len(page))
+			}
+			ids = append(ids, page[0].ID)
+			filter.After = models.CursorAfter(&page[0], filter.Sort)
+		}
+
+		if want := []int{subs[0].ID, subs[1].ID, subs[2].ID}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("paged ids = %v, want %v", ids, want)
+		}
+	})
+}
+
+func TestUpdateColumns(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	subs := []models.Subscription{
+		{ServiceName: "Kinopoisk", Price: 299, StartDate: month(time.January, 2025), EndDate: monthPtr(time.June, 2025), BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth, Currency: models.DefaultCurrency, Status: models.StatusActive},
+	}
+	createTestSubscriptions(t, repo, uuid.New(), subs)
+
+	patched := subs[0]
+	patched.EndDate, patched.Price = nil, 399
+	if err := repo.UpdateColumns(ctx, &patched, []string{"end_date"}); err != nil {
+		t.Fatalf("UpdateColumns() error: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, subs[0].ID)
+	if err != nil {
+		t.Fatalf("GetByID() error: %v", err)
+	}
+	if got.EndDate != nil {
+		t.Errorf("end_date = %v, want NULL", got.EndDate)
+	}
+	if got.Price != 299 {
+		t.Errorf("price = %d, want unchanged 299", got.Price)
+	}
+
+	if err := repo.UpdateColumns(ctx, &patched, []string{"status"}); err == nil {
+		t.Error("UpdateColumns(status): expected error")
+	}
+	patched.ID = -1
+	if err := repo.UpdateColumns(ctx, &patched, []string{"price"}); !errors.Is(err, models.ErrSubscriptionNotFound) {
+		t.Errorf("UpdateColumns() for missing subscription error = %v, want ErrSubscriptionNotFound", err)
+	}
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	subs := []models.Subscription{
+		{ServiceName: "Ivi", Price: 399, StartDate: month(time.January, 2025), BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth, Currency: models.DefaultCurrency, Status: models.StatusActive},
+	}
+	createTestSubscriptions(t, repo, uuid.New(), subs)
+	sub := subs[0]
+	if sub.Version != 1 {
+		t.Fatalf("created version = %d, want 1", sub.Version)
+	}
+
+	stale := sub
+	sub.Price = 499
+	if err := repo.Update(ctx, &sub); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	if sub.Version != 2 {
+		t.Errorf("updated version = %d, want 2", sub.Version)
+	}
+
+	stale.Price = 599
+	if err := repo.Update(ctx, &stale); !errors.Is(err, models.ErrVersionMismatch) {
+		t.Errorf("Update() with stale version error = %v, want ErrVersionMismatch", err)
+	}
+	if err := repo.Delete(ctx, sub.ID, stale.Version); !errors.Is(err, models.ErrVersionMismatch) {
+		t.Errorf("Delete() with stale version error = %v, want ErrVersionMismatch", err)
+	}
+
+	paused, err := repo.Pause(ctx, sub.ID, day(10, time.February, 2025))
+	if err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+	if paused.Version != 3 {
+		t.Errorf("version after pause = %d, want 3", paused.Version)
+	}
+
+	if err := repo.Delete(ctx, sub.ID, paused.Version); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if err := repo.Delete(ctx, sub.ID, 0); !errors.Is(err, models.ErrSubscriptionNotFound) {
+		t.Errorf("Delete() of deleted subscription error = %v, want ErrSubscriptionNotFound", err)
+	}
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	newKey := func(client, key, hash string, now time.Time) *models.IdempotencyKey {
+		return &models.IdempotencyKey{
+			Client: client, Key: key, RequestHash: hash,
+			LockedUntil: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour),
+		}
+	}
+	key := newKey("ip:127.0.0.1", uuid.NewString(), "hash", now)
+
+	saved, err := repo.ReserveIdempotencyKey(ctx, key, now)
+	if err != nil || saved != nil {
+		t.Fatalf("ReserveIdempotencyKey() = %v, %v, want nil, nil", saved, err)
+	}
+
+	// Пока блокировка не истекла, ключ занят выполняющимся запросом.
+	saved, err = repo.ReserveIdempotencyKey(ctx, newKey(key.Client, key.Key, "hash", now), now)
+	if err != nil || saved == nil || saved.StatusCode != 0 {
+		t.Fatalf("ReserveIdempotencyKey() of locked key = %+v, %v, want key in progress", saved, err)
+	}
+
+	// После истечения блокировки тот же запрос забирает ключ себе, а другой — нет.
+	leaseEnd := now.Add(2 * time.Minute)
+	saved, err = repo.ReserveIdempotencyKey(ctx, newKey(key.Client, key.Key, "other", leaseEnd), leaseEnd)
+	if err != nil || saved == nil || saved.RequestHash != "hash" {
+		t.Fatalf("ReserveIdempotencyKey() with other hash = %+v, %v, want saved key", saved, err)
+	}
+	saved, err = repo.ReserveIdempotencyKey(ctx, newKey(key.Client, key.Key, "hash", leaseEnd), leaseEnd)
+	if err != nil || saved != nil {
+		t.Fatalf("ReserveIdempotencyKey() after lease = %v, %v, want nil, nil", saved, err)
+	}
+
+	// Ключи разных клиентов не пересекаются.
+	saved, err = repo.ReserveIdempotencyKey(ctx, newKey("ip:10.0.0.1", key.Key, "other", now), now)
+	if err != nil || saved != nil {
+		t.Fatalf("ReserveIdempotencyKey() of another client = %v, %v, want nil, nil", saved, err)
+	}
+
+	key.StatusCode, key.ResponseHeader, key.ResponseBody = 201, []byte(`{"Content-Type":["application/json"]}`), []byte(`{"id":1}`)
+	if err := repo.SaveIdempotentResponse(ctx, key); err != nil {
+		t.Fatalf("SaveIdempotentResponse() error: %v", err)
+	}
+
+	saved, err = repo.ReserveIdempotencyKey(ctx, newKey(key.Client, key.Key, "other", now), now)
+	if err != nil {
+		t.Fatalf("ReserveIdempotencyKey() error: %v", err)
+	}
+	if saved == nil || saved.RequestHash != "hash" || saved.StatusCode != 201 || string(saved.ResponseBody) != `{"id":1}` {
+		t.Errorf("ReserveIdempotencyKey() of used key = %+v, want saved response", saved)
+	}
+
+	// После истечения TTL ключ можно использовать снова.
+	later := now.Add(2 * time.Hour)
+	saved, err = repo.ReserveIdempotencyKey(ctx, newKey(key.Client, key.Key, "other", later), later)
+	if err != nil || saved != nil {
+		t.Errorf("ReserveIdempotencyKey() of expired key = %v, %v, want nil, nil", saved, err)
+	}
+
+	deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx, later.Add(2*time.Hour))
+	if err != nil || deleted < 2 {
+		t.Errorf("DeleteExpiredIdempotencyKeys() = %d, %v, want at least 2", deleted, err)
+	}
+	if err := repo.DeleteIdempotencyKey(ctx, key.Client, key.Key); err != nil {
+		t.Fatalf("DeleteIdempotencyKey() error: %v", err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	newSub := func(name string) *models.Subscription {
+		return &models.Subscription{
+			ServiceName: name, Price: 199, UserID: userID, StartDate: month(time.January, 2025),
+			BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth,
+			Currency: models.DefaultCurrency, Status: models.StatusActive,
+		}
+	}
+	existing := []models.Subscription{*newSub("Wink")}
+	createTestSubscriptions(t, repo, userID, existing)
+
+	t.Run("atomic rolls back every operation", func(t *testing.T) {
+		results, err := repo.Batch(ctx, []models.BatchOperation{
+			{Kind: models.BatchCreate, Subscription: newSub("Premier")},
+			{Kind: models.BatchDelete, Subscription: &models.Subscription{ID: -1}},
+		}, true)
+		if !errors.Is(err, models.ErrBatchRolledBack) || !errors.Is(err, models.ErrSubscriptionNotFound) {
+			t.Fatalf("Batch() error = %v, want ErrBatchRolledBack with ErrSubscriptionNotFound", err)
+		}
+		if _, err := repo.GetByID(ctx, results[0].Subscription.ID); !errors.Is(err, models.ErrSubscriptionNotFound) {
+			t.Errorf("created subscription after rollback: GetByID() error = %v, want ErrSubscriptionNotFound", err)
+		}
+	})
+
+	t.Run("partial keeps successful operations", func(t *testing.T) {
+		updated := existing[0]
+		updated.Price, updated.Version = 249, existing[0].Version+1
+		results, err := repo.Batch(ctx, []models.BatchOperation{
+			{Kind: models.BatchCreate, Subscription: newSub("Start")},
+			{Kind: models.BatchUpdate, Subscription: &updated},
+			{Kind: models.BatchDelete, Subscription: &models.Subscription{ID: existing[0].ID, Version: existing[0].Version}},
+		}, false)
+		if err != nil {
+			t.Fatalf("Batch() error: %v", err)
+		}
+		if results[0].Err != nil || results[2].Err != nil {
+			t.Fatalf("Batch() results = %+v, want create and delete to succeed", results)
+		}
+		if !errors.Is(results[1].Err, models.ErrVersionMismatch) {
+			t.Errorf("update with wrong version error = %v, want ErrVersionMismatch", results[1].Err)
+		}
+
+		if _, err := repo.GetByID(ctx, results[0].Subscription.ID); err != nil {
+			t.Errorf("created subscription: GetByID() error = %v", err)
+		}
+		if _, err := repo.GetByID(ctx, existing[0].ID); !errors.Is(err, models.ErrSubscriptionNotFound) {
+			t.Errorf("deleted subscription: GetByID() error = %v, want ErrSubscriptionNotFound", err)
+		}
+	})
+	t.Run("update applies status rule of PUT", func(t *testing.T) {
+		cancelled := []models.Subscription{*newSub("Kion"), *newSub("Amediateka")}
+		createTestSubscriptions(t, repo, userID, cancelled)
+		for _, sub := range cancelled {
+			if _, err := repo.Cancel(ctx, sub.ID, month(time.March, 2025)); err != nil {
+				t.Fatalf("Cancel() error: %v", err)
+			}
+		}
+
+		// Статус из тела операции не используется: отменённая подписка без
+		// переноса end_date остаётся отменённой, а без end_date снова действует.
+		kept, reopened := *newSub("Kion"), *newSub("Amediateka")
+		kept.ID, kept.EndDate, kept.Status = cancelled[0].ID, monthPtr(time.February, 2025), ""
+		reopened.ID, reopened.Status = cancelled[1].ID, ""
+		results, err := repo.Batch(ctx, []models.BatchOperation{
+			{Kind: models.BatchUpdate, Subscription: &kept},
+			{Kind: models.BatchUpdate, Subscription: &reopened},
+		}, true)
+		if err != nil {
+			t.Fatalf("Batch() error: %v", err)
+		}
+		if got := results[0].Subscription.Status; got != models.StatusCancelled {
+			t.Errorf("status after update with earlier end_date = %s, want cancelled", got)
+		}
+		if got := results[1].Subscription.Status; got != models.StatusActive {
+			t.Errorf("status after update without end_date = %s, want active", got)
+		}
+	})
+}
+
+func TestConstraintErrors(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	sub := models.Subscription{
+		ServiceName: "Amediateka", Price: 599, StartDate: month(time.March, 2025),
+		BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth,
+		Currency: models.DefaultCurrency, Status: models.StatusActive,
+	}
+	createTestSubscriptions(t, repo, userID, []models.Subscription{sub})
+
+	duplicate := sub
+	duplicate.UserID = userID
+	if err := repo.Create(ctx, &duplicate); !errors.Is(err, models.ErrDuplicateSubscription) {
+		t.Errorf("Create() of duplicate error = %v, want ErrDuplicateSubscription", err)
+	}
+
+	invalid := sub
+	invalid.UserID, invalid.StartDate, invalid.EndDate = userID, month(time.June, 2025), monthPtr(time.April, 2025)
+	if err := repo.Create(ctx, &invalid); !errors.Is(err, models.ErrInvalidPeriod) {
+		t.Errorf("Create() with end_date before start_date error = %v, want ErrInvalidPeriod", err)
+	}
+
+	trialAfterEnd := sub
+	trialAfterEnd.UserID, trialAfterEnd.StartDate = userID, month(time.February, 2025)
+	trialAfterEnd.EndDate, trialAfterEnd.TrialEndDate = monthPtr(time.April, 2025), monthPtr(time.May, 2025)
+	if err := repo.Create(ctx, &trialAfterEnd); !errors.Is(err, models.ErrInvalidPeriod) {
+		t.Errorf("Create() with trial_end_date after end_date error = %v, want ErrInvalidPeriod", err)
+	}
+}
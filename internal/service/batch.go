@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// Batch выполняет операции над подписками в одной транзакции, см. SubsRepo.Batch.
+func (s *SubsService) Batch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error) {
+	for _, op := range ops {
+		if op.Kind == models.BatchCreate {
+			op.Subscription.Status = models.StatusActive
+		}
+	}
+
+	results, err := s.repo.Batch(ctx, ops, atomic)
+	today := time.Now().UTC()
+	failed := 0
+	for _, result := range results {
+		if result.Subscription != nil {
+			result.Subscription.Status = result.Subscription.StatusAt(today)
+		}
+		if result.Err != nil {
+			failed++
+		}
+	}
+	logger.FromContext(ctx).Info("пакет операций выполнен", "operations", len(ops), "failed", failed, "atomic", atomic, "committed", err == nil)
+	return results, err
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AntonTsoy/subscription-service/internal/billing"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func (s *SubsService) AddDiscount(ctx context.Context, discount *models.SubscriptionDiscount) error {
+	sub, err := s.repo.GetByID(ctx, discount.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if discount.StartMonth.Before(billing.MonthStart(sub.StartDate)) || (sub.EndDate != nil && discount.StartMonth.After(*sub.EndDate)) {
+		return fmt.Errorf("%w: подписка id %d, месяц %s", models.ErrDiscountOutsidePeriod, sub.ID, discount.StartMonth.Format("01-2006"))
+	}
+
+	return s.repo.AddDiscount(ctx, discount)
+}
+
+func (s *SubsService) ListDiscounts(ctx context.Context, subID int) ([]models.SubscriptionDiscount, error) {
+	if _, err := s.repo.GetByID(ctx, subID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDiscounts(ctx, []int{subID})
+}
+
+func (s *SubsService) attachDiscounts(ctx context.Context, subs []models.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	subIDs := make([]int, len(subs))
+	for i, sub := range subs {
+		subIDs[i] = sub.ID
+	}
+
+	discounts, err := s.repo.ListDiscounts(ctx, subIDs)
+	if err != nil {
+		return err
+	}
+
+	bySubID := make(map[int][]models.SubscriptionDiscount)
+	for _, discount := range discounts {
+		bySubID[discount.SubscriptionID] = append(bySubID[discount.SubscriptionID], discount)
+	}
+	for i := range subs {
+		subs[i].Discounts = bySubID[subs[i].ID]
+	}
+	return nil
+}
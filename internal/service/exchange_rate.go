@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/billing"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func (s *SubsService) SaveExchangeRate(ctx context.Context, rate *models.ExchangeRate) error {
+	return s.repo.SaveExchangeRate(ctx, rate)
+}
+
+func (s *SubsService) DeleteExchangeRate(ctx context.Context, currency string, month time.Time) error {
+	return s.repo.DeleteExchangeRate(ctx, currency, month)
+}
+
+func (s *SubsService) ListExchangeRates(ctx context.Context, filter *models.ExchangeRatesFilter) ([]models.ExchangeRate, error) {
+	return s.repo.ListExchangeRates(ctx, filter)
+}
+
+// ratesFor загружает курсы, нужные для пересчёта начислений подписок subs в валюту target.
+func (s *SubsService) ratesFor(ctx context.Context, subs []models.Subscription, window billing.Period, target string) ([]models.ExchangeRate, error) {
+	currencies := rateCurrencies(subs, target)
+	if len(currencies) == 0 {
+		return nil, nil
+	}
+
+	return s.repo.ListExchangeRates(ctx, &models.ExchangeRatesFilter{
+		Currencies: currencies,
+		From:       window.Start,
+		To:         window.End,
+	})
+}
+
+// rateCurrencies возвращает валюты подписок subs и валюту target, для которых нужны курсы.
+func rateCurrencies(subs []models.Subscription, target string) []string {
+	seen := make(map[string]struct{})
+	var currencies []string
+	for _, currency := range append([]string{target}, subsCurrencies(subs)...) {
+		if _, ok := seen[currency]; ok || currency == "" || currency == models.DefaultCurrency {
+			continue
+		}
+		seen[currency] = struct{}{}
+		currencies = append(currencies, currency)
+	}
+	return currencies
+}
+
+func subsCurrencies(subs []models.Subscription) []string {
+	currencies := make([]string, len(subs))
+	for i, sub := range subs {
+		currencies[i] = sub.Currency
+	}
+	return currencies
+}
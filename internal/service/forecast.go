@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AntonTsoy/subscription-service/internal/billing"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// EvaluateForecastCost прогнозирует расходы на подписки на months месяцев вперёд,
+// начиная со следующего месяца. Бессрочные подписки продолжаются, завершённые
+// заканчиваются в свой end_date. Курсы валют на будущие месяцы берутся последние известные.
+func (s *SubsService) EvaluateForecastCost(ctx context.Context, subParams *models.ListSubscriptionsParams, months int) ([]models.MonthlyCost, int, error) {
+	window := billing.NextMonths(s.now(), months)
+	subParams.StartDate, subParams.EndDate = window.Start, window.End
+
+	subs, err := s.listWithDetails(ctx, subParams)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rates []models.ExchangeRate
+	if currencies := rateCurrencies(subs, subParams.Options.Currency); len(currencies) > 0 {
+		rates, err = s.repo.ListExchangeRates(ctx, &models.ExchangeRatesFilter{Currencies: currencies, To: window.End})
+		if err != nil {
+			return nil, 0, err
+		}
+		rates = billing.ForecastRates(rates, window)
+	}
+
+	costs, err := billing.MonthlyCosts(subs, window, subParams.Options, rates)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalCost := 0
+	for _, cost := range costs {
+		totalCost += cost.Cost
+	}
+	return costs, totalCost, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// forecastRepo отдаёт заранее заданные подписки и курсы. Остальные методы
+// репозитория прогнозу не нужны и не реализованы.
+type forecastRepo struct {
+	SubscriptionRepository
+	subs   []models.Subscription
+	rates  []models.ExchangeRate
+	params *models.ListSubscriptionsParams
+}
+
+func (r *forecastRepo) ListByUserAndService(ctx context.Context, params *models.ListSubscriptionsParams) ([]models.Subscription, error) {
+	r.params = params
+	return r.subs, nil
+}
+
+func (r *forecastRepo) ListPrices(ctx context.Context, subIDs []int) ([]models.SubscriptionPrice, error) {
+	return nil, nil
+}
+
+func (r *forecastRepo) ListDiscounts(ctx context.Context, subIDs []int) ([]models.SubscriptionDiscount, error) {
+	return nil, nil
+}
+
+func (r *forecastRepo) ListPauses(ctx context.Context, subIDs []int) ([]models.SubscriptionPause, error) {
+	return nil, nil
+}
+
+func (r *forecastRepo) ListExchangeRates(ctx context.Context, filter *models.ExchangeRatesFilter) ([]models.ExchangeRate, error) {
+	return r.rates, nil
+}
+
+func TestEvaluateForecastCost(t *testing.T) {
+	month := func(m time.Month, year int) time.Time {
+		return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	spotifyEnd := month(time.December, 2025)
+
+	repo := &forecastRepo{
+		subs: []models.Subscription{
+			{ID: 1, ServiceName: "Netflix", Price: 500, StartDate: month(time.January, 2025), Currency: models.DefaultCurrency},
+			{ID: 2, ServiceName: "Spotify", Price: 300, StartDate: month(time.March, 2025), EndDate: &spotifyEnd, Currency: models.DefaultCurrency},
+			{ID: 3, ServiceName: "ChatGPT", Price: 20, StartDate: month(time.June, 2025), Currency: "USD"},
+		},
+		// Курса на будущие месяцы ещё нет: прогноз берёт последний известный.
+		rates: []models.ExchangeRate{{Currency: "USD", Month: month(time.October, 2025), Rate: 90}},
+	}
+	s := NewSubsService(repo)
+	s.now = func() time.Time { return time.Date(2025, time.October, 17, 12, 0, 0, 0, time.UTC) }
+
+	costs, total, err := s.EvaluateForecastCost(context.Background(), &models.ListSubscriptionsParams{}, 3)
+	if err != nil {
+		t.Fatalf("EvaluateForecastCost() error: %v", err)
+	}
+
+	if repo.params.StartDate != month(time.November, 2025) || repo.params.EndDate != month(time.January, 2026) {
+		t.Errorf("окно прогноза = %s - %s, want 11-2025 - 01-2026", repo.params.StartDate.Format("01-2006"), repo.params.EndDate.Format("01-2006"))
+	}
+
+	var months []string
+	var got []int
+	for _, cost := range costs {
+		months = append(months, cost.Month.Format("01-2006"))
+		got = append(got, cost.Cost)
+	}
+	if want := []string{"11-2025", "12-2025", "01-2026"}; !reflect.DeepEqual(months, want) {
+		t.Errorf("months = %v, want %v", months, want)
+	}
+	if want := []int{500 + 300 + 1800, 500 + 300 + 1800, 500 + 1800}; !reflect.DeepEqual(got, want) {
+		t.Errorf("costs = %v, want %v", got, want)
+	}
+	if total != 2600+2600+2300 {
+		t.Errorf("total = %d, want %d", total, 2600+2600+2300)
+	}
+}
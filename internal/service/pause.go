@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func (s *SubsService) AddPause(ctx context.Context, pause *models.SubscriptionPause) error {
+	return s.repo.AddPause(ctx, pause)
+}
+
+func (s *SubsService) ListPauses(ctx context.Context, subID int) ([]models.SubscriptionPause, error) {
+	if _, err := s.repo.GetByID(ctx, subID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPauses(ctx, []int{subID})
+}
+
+func (s *SubsService) DeletePause(ctx context.Context, subID, pauseID int) error {
+	return s.repo.DeletePause(ctx, subID, pauseID)
+}
+
+func (s *SubsService) attachPauses(ctx context.Context, subs []models.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	subIDs := make([]int, len(subs))
+	for i, sub := range subs {
+		subIDs[i] = sub.ID
+	}
+
+	pauses, err := s.repo.ListPauses(ctx, subIDs)
+	if err != nil {
+		return err
+	}
+
+	bySubID := make(map[int][]models.SubscriptionPause)
+	for _, pause := range pauses {
+		bySubID[pause.SubscriptionID] = append(bySubID[pause.SubscriptionID], pause)
+	}
+	for i := range subs {
+		subs[i].Pauses = bySubID[subs[i].ID]
+	}
+	return nil
+}
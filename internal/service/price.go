@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AntonTsoy/subscription-service/internal/billing"
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func (s *SubsService) AddPrice(ctx context.Context, price *models.SubscriptionPrice) (bool, error) {
+	sub, err := s.repo.GetByID(ctx, price.SubscriptionID)
+	if err != nil {
+		return false, err
+	}
+
+	if price.EffectiveFrom.Before(billing.MonthStart(sub.StartDate)) || (sub.EndDate != nil && price.EffectiveFrom.After(*sub.EndDate)) {
+		return false, fmt.Errorf("%w: подписка id %d, месяц %s", models.ErrPriceOutsidePeriod, sub.ID, price.EffectiveFrom.Format("01-2006"))
+	}
+
+	replaced, err := s.repo.AddPrice(ctx, price)
+	if replaced {
+		logger.FromContext(ctx).Info("изменение цены подписки заменено", "id", sub.ID, "effective_from", price.EffectiveFrom.Format("01-2006"))
+	}
+	return replaced, err
+}
+
+func (s *SubsService) ListPrices(ctx context.Context, subID int) ([]models.SubscriptionPrice, error) {
+	if _, err := s.repo.GetByID(ctx, subID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListPrices(ctx, []int{subID})
+}
+
+func (s *SubsService) attachPrices(ctx context.Context, subs []models.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	subIDs := make([]int, len(subs))
+	for i, sub := range subs {
+		subIDs[i] = sub.ID
+	}
+
+	prices, err := s.repo.ListPrices(ctx, subIDs)
+	if err != nil {
+		return err
+	}
+
+	bySubID := make(map[int][]models.SubscriptionPrice)
+	for _, price := range prices {
+		bySubID[price.SubscriptionID] = append(bySubID[price.SubscriptionID], price)
+	}
+	for i := range subs {
+		subs[i].Prices = bySubID[subs[i].ID]
+	}
+	return nil
+}
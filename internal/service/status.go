@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+func (s *SubsService) Pause(ctx context.Context, id int) (*models.Subscription, error) {
+	return s.transition(ctx, id, s.repo.Pause)
+}
+
+func (s *SubsService) Resume(ctx context.Context, id int) (*models.Subscription, error) {
+	return s.transition(ctx, id, s.repo.Resume)
+}
+
+func (s *SubsService) Cancel(ctx context.Context, id int) (*models.Subscription, error) {
+	return s.transition(ctx, id, s.repo.Cancel)
+}
+
+// transition меняет статус подписки методом репозитория change и логирует новый статус.
+func (s *SubsService) transition(ctx context.Context, id int, change func(context.Context, int, time.Time) (*models.Subscription, error)) (*models.Subscription, error) {
+	sub, err := s.withStatus(change(ctx, id, s.now()))
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("статус подписки изменён", "id", id, "status", sub.Status)
+	return sub, nil
+}
+
+// withStatus заменяет сохранённый статус подписки статусом на сегодня.
+func (s *SubsService) withStatus(sub *models.Subscription, err error) (*models.Subscription, error) {
+	if err != nil {
+		return nil, err
+	}
+	sub.Status = sub.StatusAt(s.now())
+	return sub, nil
+}
@@ -2,63 +2,239 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/AntonTsoy/subscription-service/internal/billing"
+	"github.com/AntonTsoy/subscription-service/internal/logger"
 	"github.com/AntonTsoy/subscription-service/internal/models"
 )
 
 type SubscriptionRepository interface {
 	Create(ctx context.Context, sub *models.Subscription) error
 	GetByID(ctx context.Context, id int) (*models.Subscription, error)
-	GetAll(ctx context.Context, limit, offset int) ([]models.Subscription, error)
+	GetAll(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, error)
+	GetAllWithCount(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, int, error)
 	Update(ctx context.Context, sub *models.Subscription) error
-	Delete(ctx context.Context, id int) error
+	UpdateColumns(ctx context.Context, sub *models.Subscription, columns []string) error
+	Delete(ctx context.Context, id, version int) error
+	Batch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error)
 	ListByUserAndService(ctx context.Context, params *models.ListSubscriptionsParams) ([]models.Subscription, error)
+	AddPrice(ctx context.Context, price *models.SubscriptionPrice) (bool, error)
+	ListPrices(ctx context.Context, subIDs []int) ([]models.SubscriptionPrice, error)
+	AddDiscount(ctx context.Context, discount *models.SubscriptionDiscount) error
+	ListDiscounts(ctx context.Context, subIDs []int) ([]models.SubscriptionDiscount, error)
+	Pause(ctx context.Context, id int, today time.Time) (*models.Subscription, error)
+	Resume(ctx context.Context, id int, today time.Time) (*models.Subscription, error)
+	Cancel(ctx context.Context, id int, today time.Time) (*models.Subscription, error)
+	AddPause(ctx context.Context, pause *models.SubscriptionPause) error
+	ListPauses(ctx context.Context, subIDs []int) ([]models.SubscriptionPause, error)
+	DeletePause(ctx context.Context, subID, pauseID int) error
+	SaveExchangeRate(ctx context.Context, rate *models.ExchangeRate) error
+	DeleteExchangeRate(ctx context.Context, currency string, month time.Time) error
+	ListExchangeRates(ctx context.Context, filter *models.ExchangeRatesFilter) ([]models.ExchangeRate, error)
+	TotalCost(ctx context.Context, params *models.ListSubscriptionsParams) (int, error)
+	TotalCostGroupedBy(ctx context.Context, params *models.ListSubscriptionsParams, groupBy models.CostGroupBy) ([]models.GroupCost, error)
 }
 
 type SubsService struct {
 	repo SubscriptionRepository
+	// now — текущее время, от него считаются статусы подписок и окно прогноза.
+	now func() time.Time
 }
 
 func NewSubsService(repo SubscriptionRepository) *SubsService {
-	return &SubsService{repo: repo}
+	return &SubsService{repo: repo, now: func() time.Time { return time.Now().UTC() }}
 }
 
 func (s *SubsService) Create(ctx context.Context, sub *models.Subscription) error {
+	sub.Status = models.StatusActive
 	return s.repo.Create(ctx, sub)
 }
 
 func (s *SubsService) GetByID(ctx context.Context, id int) (*models.Subscription, error) {
-	return s.repo.GetByID(ctx, id)
+	return s.withStatus(s.repo.GetByID(ctx, id))
 }
 
-func (s *SubsService) GetAll(ctx context.Context, limit, offset int) ([]models.Subscription, error) {
-	return s.repo.GetAll(ctx, limit, offset)
+func (s *SubsService) GetAll(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, error) {
+	subs, err := s.repo.GetAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.now()
+	for i := range subs {
+		subs[i].Status = subs[i].StatusAt(today)
+	}
+	return subs, nil
+}
+
+// GetAllWithCount возвращает страницу подписок и число всех подписок под фильтрами.
+func (s *SubsService) GetAllWithCount(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, int, error) {
+	subs, total, err := s.repo.GetAllWithCount(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	today := s.now()
+	for i := range subs {
+		subs[i].Status = subs[i].StatusAt(today)
+	}
+	return subs, total, nil
+}
+
+// GetPage возвращает страницу из filter.Limit (не меньше одной) подписок после курсора
+// filter.After, курсор следующей страницы или nil, если страница последняя, и число
+// всех подписок под фильтрами.
+func (s *SubsService) GetPage(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, *models.Cursor, int, error) {
+	// Лишняя подписка показывает, что за страницей есть продолжение.
+	page := *filter
+	page.Limit = max(filter.Limit, 1) + 1
+
+	subs, total, err := s.GetAllWithCount(ctx, &page)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(subs) < page.Limit {
+		return subs, nil, total, nil
+	}
+
+	subs = subs[:page.Limit-1]
+	return subs, models.CursorAfter(&subs[len(subs)-1], filter.Sort), total, nil
 }
 
 func (s *SubsService) Update(ctx context.Context, sub *models.Subscription) error {
+	current, err := s.repo.GetByID(ctx, sub.ID)
+	if err != nil {
+		return err
+	}
+	if sub.Version != 0 && sub.Version != current.Version {
+		return fmt.Errorf("%w: подписка id %d, версия %d вместо %d", models.ErrVersionMismatch, sub.ID, current.Version, sub.Version)
+	}
+
+	sub.Status = current.StatusAfterEdit(sub)
+	logStatusChange(ctx, current, sub)
+	// Статус посчитан по прочитанной подписке: её версия защищает от изменений между чтением и записью.
+	sub.Version = current.Version
 	return s.repo.Update(ctx, sub)
 }
 
-func (s *SubsService) Delete(ctx context.Context, id int) error {
-	return s.repo.Delete(ctx, id)
+// Patch применяет к подписке функцию apply и сохраняет только изменившиеся колонки.
+// version — ожидаемая версия подписки (0 — любая). Ошибки apply оборачиваются
+// в models.ErrInvalidPatch с сохранением исходной ошибки.
+func (s *SubsService) Patch(ctx context.Context, id, version int, apply func(*models.Subscription) (*models.Subscription, error)) (*models.Subscription, error) {
+	current, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && version != current.Version {
+		return nil, fmt.Errorf("%w: подписка id %d, версия %d вместо %d", models.ErrVersionMismatch, id, current.Version, version)
+	}
+
+	patched, err := apply(current)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidPatch, err)
+	}
+	patched.Status = current.StatusAfterEdit(patched)
+	logStatusChange(ctx, current, patched)
+	// Версия прочитанной подписки защищает от изменений между чтением и записью.
+	patched.ID, patched.Version = current.ID, current.Version
+	columns := changedColumns(current, patched)
+	logger.FromContext(ctx).Debug("частичное обновление подписки", "id", id, "columns", columns)
+	if err := s.repo.UpdateColumns(ctx, patched, columns); err != nil {
+		return nil, err
+	}
+	return s.withStatus(patched, nil)
+}
+
+// logStatusChange логирует смену статуса подписки при её редактировании,
+// например возобновление отменённой подписки продлением end_date.
+func logStatusChange(ctx context.Context, old, new *models.Subscription) {
+	if old.Status != new.Status {
+		logger.FromContext(ctx).Info("статус подписки изменён редактированием", "id", old.ID, "from", old.Status, "to", new.Status)
+	}
+}
+
+// changedColumns возвращает колонки, значения которых у подписок old и new различаются.
+func changedColumns(old, new *models.Subscription) []string {
+	var columns []string
+	add := func(column string, changed bool) {
+		if changed {
+			columns = append(columns, column)
+		}
+	}
+
+	add("service_name", old.ServiceName != new.ServiceName)
+	add("price", old.Price != new.Price)
+	add("user_id", old.UserID != new.UserID)
+	add("start_date", !old.StartDate.Equal(new.StartDate))
+	add("end_date", !equalDates(old.EndDate, new.EndDate))
+	add("billing_period", old.BillingPeriod != new.BillingPeriod)
+	add("date_precision", old.DatePrecision != new.DatePrecision)
+	add("currency", old.Currency != new.Currency)
+	add("trial_end_date", !equalDates(old.TrialEndDate, new.TrialEndDate))
+	add("status", old.Status != new.Status)
+	return columns
+}
+
+func equalDates(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func (s *SubsService) Delete(ctx context.Context, id, version int) error {
+	return s.repo.Delete(ctx, id, version)
 }
 
 func (s *SubsService) EvaluateTotalServiceSubscriptionsCost(ctx context.Context, subParams *models.ListSubscriptionsParams) (int, error) {
-	subs, err := s.repo.ListByUserAndService(ctx, subParams)
+	return s.repo.TotalCost(ctx, subParams)
+}
+
+func (s *SubsService) EvaluateGroupedServiceSubscriptionsCost(ctx context.Context, subParams *models.ListSubscriptionsParams, groupBy models.CostGroupBy) ([]models.GroupCost, int, error) {
+	groups, err := s.repo.TotalCostGroupedBy(ctx, subParams, groupBy)
 	if err != nil {
-		return 0, err
+		return nil, 0, err
 	}
 
 	totalCost := 0
-	for _, sub := range subs {
-		if sub.StartDate.Before(subParams.StartDate) {
-			sub.StartDate = subParams.StartDate
-		}
-		if sub.EndDate == nil || sub.EndDate.After(subParams.EndDate) {
-			sub.EndDate = &subParams.EndDate
-		}
+	for _, group := range groups {
+		totalCost += group.Cost
+	}
+	return groups, totalCost, nil
+}
+
+func (s *SubsService) EvaluateMonthlyServiceSubscriptionsCost(ctx context.Context, subParams *models.ListSubscriptionsParams) ([]models.MonthlyCost, error) {
+	subs, err := s.listWithDetails(ctx, subParams)
+	if err != nil {
+		return nil, err
+	}
+
+	window := billing.Period{Start: subParams.StartDate, End: subParams.EndDate}
 
-		totalCost += (1 + int(sub.EndDate.Month()) - int(sub.StartDate.Month())) * sub.Price
+	rates, err := s.ratesFor(ctx, subs, window, subParams.Options.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return billing.MonthlyCosts(subs, window, subParams.Options, rates)
+}
+
+// listWithDetails загружает подписки вместе с историей цен, скидками и паузами,
+// которые нужны для расчёта начислений на стороне приложения.
+func (s *SubsService) listWithDetails(ctx context.Context, subParams *models.ListSubscriptionsParams) ([]models.Subscription, error) {
+	subs, err := s.repo.ListByUserAndService(ctx, subParams)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachPrices(ctx, subs); err != nil {
+		return nil, err
+	}
+	if err := s.attachDiscounts(ctx, subs); err != nil {
+		return nil, err
+	}
+	if err := s.attachPauses(ctx, subs); err != nil {
+		return nil, err
 	}
-	return totalCost, nil
+	return subs, nil
 }
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/models"
+)
+
+// editRepo хранит одну подписку и запоминает, что с ней сделали Update и UpdateColumns.
+type editRepo struct {
+	SubscriptionRepository
+	sub     models.Subscription
+	columns []string
+}
+
+func (r *editRepo) GetByID(ctx context.Context, id int) (*models.Subscription, error) {
+	sub := r.sub
+	return &sub, nil
+}
+
+func (r *editRepo) Update(ctx context.Context, sub *models.Subscription) error {
+	r.sub = *sub
+	return nil
+}
+
+func (r *editRepo) UpdateColumns(ctx context.Context, sub *models.Subscription, columns []string) error {
+	r.sub, r.columns = *sub, columns
+	return nil
+}
+
+func TestEditReopensCancelledSubscription(t *testing.T) {
+	month := func(m time.Month, year int) *time.Time {
+		t := time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	cancelled := models.Subscription{
+		ID: 1, ServiceName: "Netflix", Price: 542, StartDate: *month(time.January, 2025), EndDate: month(time.June, 2025),
+		BillingPeriod: models.BillingMonthly, DatePrecision: models.PrecisionMonth, Currency: models.DefaultCurrency,
+		Status: models.StatusCancelled,
+	}
+
+	tests := []struct {
+		name    string
+		endDate *time.Time
+		want    models.SubscriptionStatus
+	}{
+		{"end_date cleared", nil, models.StatusActive},
+		{"end_date moved later", month(time.December, 2025), models.StatusActive},
+		{"end_date unchanged", month(time.June, 2025), models.StatusCancelled},
+		{"end_date moved earlier", month(time.May, 2025), models.StatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run("update "+tt.name, func(t *testing.T) {
+			repo := &editRepo{sub: cancelled}
+			sub := cancelled
+			sub.EndDate, sub.Status = tt.endDate, ""
+
+			if err := NewSubsService(repo).Update(context.Background(), &sub); err != nil {
+				t.Fatalf("Update() error: %v", err)
+			}
+			if repo.sub.Status != tt.want {
+				t.Errorf("status = %s, want %s", repo.sub.Status, tt.want)
+			}
+		})
+
+		t.Run("patch "+tt.name, func(t *testing.T) {
+			repo := &editRepo{sub: cancelled}
+			apply := func(sub *models.Subscription) (*models.Subscription, error) {
+				patched := *sub
+				patched.EndDate = tt.endDate
+				return &patched, nil
+			}
+
+			if _, err := NewSubsService(repo).Patch(context.Background(), cancelled.ID, 0, apply); err != nil {
+				t.Fatalf("Patch() error: %v", err)
+			}
+			if repo.sub.Status != tt.want {
+				t.Errorf("status = %s, want %s", repo.sub.Status, tt.want)
+			}
+		})
+	}
+}
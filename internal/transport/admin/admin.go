@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+// RequireToken пропускает запрос дальше, только если в заголовке Authorization
+// передан токен администратора: "Bearer <token>". Пустой token закрывает доступ
+// ко всем защищённым ручкам, чтобы незаданная переменная окружения не открыла их.
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				logger.FromContext(r.Context()).Warn("запрос к административной ручке без верного токена")
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				problem.Write(w, r, http.StatusUnauthorized, "missing or invalid admin token")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -1,58 +1,441 @@
 package dto
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AntonTsoy/subscription-service/internal/models"
 	"github.com/google/uuid"
 )
 
-const layout = "01-2006"
+const (
+	layout    = "01-2006"
+	dayLayout = "2006-01-02"
 
+	defaultForecastMonths = 12
+	maxForecastMonths     = 120
+
+	// MaxMonthlyCostMonths — наибольшая длина периода помесячной разбивки стоимости.
+	MaxMonthlyCostMonths = 120
+
+	// MaxBatchOperations — наибольшее число операций в пакетном запросе.
+	MaxBatchOperations = 1000
+)
+
+// ToSubscription проверяет сразу все поля запроса и возвращает *ValidationError
+// со всеми нарушениями.
 func ToSubscription(req *SubscriptionRequest) (*models.Subscription, error) {
-	userID, err := uuid.Parse(req.UserID)
+	v := &validator{}
+	sub := toSubscription(req, v)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func toSubscription(req *SubscriptionRequest, v *validator) *models.Subscription {
+	sub := &models.Subscription{
+		ServiceName:   req.ServiceName,
+		Price:         req.Price,
+		BillingPeriod: models.BillingMonthly,
+		Currency:      models.DefaultCurrency,
+	}
+
+	if strings.TrimSpace(req.ServiceName) == "" {
+		v.add("service_name", CodeRequired, "%s is required")
+	}
+	if req.Price < 0 {
+		v.add("price", CodeMin, "%s must not be negative")
+	}
+
+	if req.UserID == "" {
+		v.add("user_id", CodeRequired, "%s is required")
+	} else if userID, err := uuid.Parse(req.UserID); err != nil {
+		v.add("user_id", CodeInvalidFormat, "%s must be a UUID")
+	} else {
+		sub.UserID = userID
+	}
+
+	startValid := false
+	if req.StartDate == "" {
+		v.add("start_date", CodeRequired, "%s is required")
+	} else if start, precision, err := parseDate(req.StartDate); err != nil {
+		v.add("start_date", CodeInvalidFormat, "%s must be MM-YYYY or YYYY-MM-DD")
+	} else {
+		sub.StartDate, sub.DatePrecision, startValid = start, precision, true
+	}
+
+	sub.EndDate = laterDate(v, "end_date", req.EndDate, sub, startValid)
+	sub.TrialEndDate = laterDate(v, "trial_end_date", req.TrialEndDate, sub, startValid)
+	if sub.EndDate != nil && sub.TrialEndDate != nil && sub.TrialEndDate.After(*sub.EndDate) {
+		v.add("trial_end_date", CodeDateOrder, "%s must not be after end_date")
+	}
+
+	if req.BillingPeriod != "" {
+		if period, err := ToBillingPeriod(req.BillingPeriod); err != nil {
+			v.add("billing_period", CodeInvalidValue, "%s must be one of %s", "weekly, monthly, quarterly, yearly")
+		} else {
+			sub.BillingPeriod = period
+		}
+	}
+	if req.Currency != "" {
+		if currency, err := ToCurrency(req.Currency); err != nil {
+			v.add("currency", CodeInvalidValue, "%s must be an ISO 4217 code like USD")
+		} else {
+			sub.Currency = currency
+		}
+	}
+	return sub
+}
+
+// laterDate разбирает необязательную дату подписки: она должна быть в том же
+// формате, что и start_date, и не раньше неё.
+func laterDate(v *validator, field, value string, sub *models.Subscription, startValid bool) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	t, precision, err := parseDate(value)
 	if err != nil {
-		return nil, fmt.Errorf("неверный формат user_id: %w", err)
+		v.add(field, CodeInvalidFormat, "%s must be MM-YYYY or YYYY-MM-DD")
+		return nil
 	}
 
-	start, err := time.Parse(layout, req.StartDate)
+	switch {
+	case !startValid:
+	case precision != sub.DatePrecision:
+		v.add(field, CodeFormatMismatch, "%s must use the same format as start_date")
+	case t.Before(sub.StartDate):
+		v.add(field, CodeDateOrder, "%s must not be before start_date")
+	}
+	return &t
+}
+
+// mergePatchFields — поля подписки, которые можно менять через PATCH.
+// Обязательные поля нельзя удалить через null.
+var mergePatchFields = map[string]struct{ required bool }{
+	"service_name":   {required: true},
+	"price":          {required: true},
+	"user_id":        {required: true},
+	"start_date":     {required: true},
+	"end_date":       {},
+	"billing_period": {},
+	"currency":       {},
+	"trial_end_date": {},
+}
+
+// ApplyMergePatch применяет к подписке JSON Merge Patch (RFC 7386): переданные поля
+// заменяются, null удаляет необязательное поле (например, end_date открывает подписку заново).
+// Результат проверяется так же, как тело PUT.
+func ApplyMergePatch(sub *models.Subscription, patch []byte) (*models.Subscription, error) {
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return nil, fmt.Errorf("неверный формат патча: %w", err)
+	}
+	if changes == nil {
+		return nil, fmt.Errorf("патч должен быть JSON-объектом")
+	}
+
+	current, err := json.Marshal(toSubscriptionRequest(sub))
 	if err != nil {
-		return nil, fmt.Errorf("неверный формат start_date: %w", err)
+		return nil, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(current, &doc); err != nil {
+		return nil, err
 	}
 
-	var end *time.Time
-	if req.EndDate != "" {
-		t, err := time.Parse(layout, req.EndDate)
-		if err != nil {
-			return nil, fmt.Errorf("неверный формат end_date: %w", err)
+	v := &validator{}
+	for name, value := range changes {
+		field, ok := mergePatchFields[name]
+		switch {
+		case !ok:
+			v.add(name, CodeUnknownField, "%s cannot be changed")
+		case string(value) != "null":
+			doc[name] = value
+		case field.required:
+			v.add(name, CodeRequired, "%s is required and cannot be null")
+		default:
+			delete(doc, name)
 		}
-		end = &t
+	}
+	if err := v.err(); err != nil {
+		return nil, err
 	}
 
-	return &models.Subscription{
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      userID,
-		StartDate:   start,
-		EndDate:     end,
-	}, nil
+	merged, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var req SubscriptionRequest
+	if err := json.Unmarshal(merged, &req); err != nil {
+		if verr := DecodeValidationError(err); verr != nil {
+			return nil, verr
+		}
+		return nil, fmt.Errorf("неверное значение в патче: %w", err)
+	}
+
+	patched, err := ToSubscription(&req)
+	if err != nil {
+		return nil, err
+	}
+	patched.ID, patched.Status = sub.ID, sub.Status
+	return patched, nil
+}
+
+func toSubscriptionRequest(sub *models.Subscription) *SubscriptionRequest {
+	resp := ToSubscriptionResponse(sub)
+	return &SubscriptionRequest{
+		ServiceName:   resp.ServiceName,
+		Price:         resp.Price,
+		UserID:        resp.UserID,
+		StartDate:     resp.StartDate,
+		EndDate:       resp.EndDate,
+		BillingPeriod: resp.BillingPeriod,
+		Currency:      resp.Currency,
+		TrialEndDate:  resp.TrialEndDate,
+	}
+}
+
+// ToCurrency проверяет трёхбуквенный код валюты ISO 4217.
+func ToCurrency(value string) (string, error) {
+	if len(value) != 3 || strings.ContainsFunc(value, func(r rune) bool { return r < 'A' || r > 'Z' }) {
+		return "", fmt.Errorf("неверный код валюты: %q", value)
+	}
+	return value, nil
+}
+
+// parseDate разбирает дату в формате MM-YYYY (точность до месяца) или YYYY-MM-DD (точность до дня).
+func parseDate(value string) (time.Time, models.DatePrecision, error) {
+	if t, err := time.Parse(layout, value); err == nil {
+		return t, models.PrecisionMonth, nil
+	}
+
+	t, err := time.Parse(dayLayout, value)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("ожидается MM-YYYY или YYYY-MM-DD: %w", err)
+	}
+	return t, models.PrecisionDay, nil
+}
+
+func formatDate(t time.Time, precision models.DatePrecision) string {
+	if precision == models.PrecisionDay {
+		return t.Format(dayLayout)
+	}
+	return t.Format(layout)
+}
+
+func ToBillingPeriod(value string) (models.BillingPeriod, error) {
+	switch period := models.BillingPeriod(value); period {
+	case models.BillingWeekly, models.BillingMonthly, models.BillingQuarterly, models.BillingYearly:
+		return period, nil
+	default:
+		return "", fmt.Errorf("неизвестное значение billing_period: %q", value)
+	}
+}
+
+func ToSubscriptionListResponse(subs []models.Subscription, total, limit, offset int, next *string, nextCursor *models.Cursor) *SubscriptionListResponse {
+	resp := SubscriptionListResponse{
+		Items:      make([]SubscriptionResponse, len(subs)),
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		Next:       next,
+		NextCursor: EncodeCursor(nextCursor),
+	}
+	for i, sub := range subs {
+		resp.Items[i] = *ToSubscriptionResponse(&sub)
+	}
+	return &resp
 }
 
 func ToSubscriptionResponse(sub *models.Subscription) *SubscriptionResponse {
 	resp := SubscriptionResponse{
-		ID:          sub.ID,
-		ServiceName: sub.ServiceName,
-		Price:       sub.Price,
-		UserID:      sub.UserID.String(),
-		StartDate:   sub.StartDate.Format(layout),
+		ID:            sub.ID,
+		ServiceName:   sub.ServiceName,
+		Price:         sub.Price,
+		UserID:        sub.UserID.String(),
+		StartDate:     formatDate(sub.StartDate, sub.DatePrecision),
+		BillingPeriod: string(sub.BillingPeriod),
+		Currency:      sub.Currency,
+		Status:        string(sub.Status),
 	}
 	if sub.EndDate != nil {
-		resp.EndDate = sub.EndDate.Format(layout)
+		resp.EndDate = formatDate(*sub.EndDate, sub.DatePrecision)
+	}
+	if sub.TrialEndDate != nil {
+		resp.TrialEndDate = formatDate(*sub.TrialEndDate, sub.DatePrecision)
 	}
 	return &resp
 }
 
+func ToSubscriptionFilter(req *SubscriptionListRequest) (*models.SubscriptionFilter, error) {
+	filter := &models.SubscriptionFilter{}
+
+	if req.UserID != "" {
+		id, err := uuid.Parse(req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("неверный формат user_id: %w", err)
+		}
+		filter.UserID = &id
+	}
+
+	if req.ServiceName != "" {
+		filter.ServiceName = &req.ServiceName
+	}
+	if req.ServiceNamePrefix != "" {
+		filter.ServiceNamePrefix = &req.ServiceNamePrefix
+	}
+
+	var err error
+	if filter.MinPrice, err = parseOptionalInt(req.MinPrice, "price_min"); err != nil {
+		return nil, err
+	}
+	if filter.MaxPrice, err = parseOptionalInt(req.MaxPrice, "price_max"); err != nil {
+		return nil, err
+	}
+
+	if req.ActiveOn != "" {
+		activeOn, err := time.Parse(layout, req.ActiveOn)
+		if err != nil {
+			return nil, fmt.Errorf("неверный формат active_on: %w", err)
+		}
+		filter.ActiveOn = &activeOn
+	}
+
+	if req.OpenEnded != "" {
+		filter.OpenEnded, err = strconv.ParseBool(req.OpenEnded)
+		if err != nil {
+			return nil, fmt.Errorf("неверный формат open_ended: %w", err)
+		}
+	}
+
+	filter.Sort, err = ToSortFields(req.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Cursor != "" {
+		filter.After, err = ToCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if req.Sort != "" && formatSort(models.SortWithID(filter.Sort)) != formatSort(filter.After.Sort) {
+			return nil, fmt.Errorf("sort не совпадает с сортировкой курсора")
+		}
+		filter.Sort = filter.After.Sort
+	}
+	return filter, nil
+}
+
+// ToCursor декодирует непрозрачный курсор, выданный в next_cursor.
+func ToCursor(value string) (*models.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат cursor: %w", err)
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("неверный формат cursor: %w", err)
+	}
+
+	sort, err := ToSortFields(payload.Sort)
+	if err != nil {
+		return nil, fmt.Errorf("неверная сортировка в cursor: %w", err)
+	}
+	if len(sort) == 0 || len(sort) != len(payload.Values) {
+		return nil, fmt.Errorf("неверный формат cursor: %d полей и %d значений", len(sort), len(payload.Values))
+	}
+	for i, field := range sort {
+		if err := checkCursorValue(field.Field, payload.Values[i]); err != nil {
+			return nil, fmt.Errorf("неверное значение %s в cursor: %w", field.Field, err)
+		}
+	}
+	return &models.Cursor{Sort: sort, Values: payload.Values}, nil
+}
+
+// checkCursorValue проверяет, что значение из курсора приводится к типу поля
+// сортировки: изменённый клиентом курсор не должен доходить до PostgreSQL.
+func checkCursorValue(field, value string) error {
+	switch models.SortableFields[field] {
+	case models.SortInt:
+		_, err := strconv.ParseInt(value, 10, 32)
+		return err
+	case models.SortDate:
+		if field == "end_date" && value == "infinity" {
+			return nil
+		}
+		_, err := time.Parse(dayLayout, value)
+		return err
+	case models.SortUUID:
+		_, err := uuid.Parse(value)
+		return err
+	default:
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("недопустимый символ NUL")
+		}
+		return nil
+	}
+}
+
+func EncodeCursor(cursor *models.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(cursorPayload{Sort: formatSort(cursor.Sort), Values: cursor.Values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func formatSort(sort []models.SortField) string {
+	names := make([]string, len(sort))
+	for i, field := range sort {
+		names[i] = field.Field
+		if field.Desc {
+			names[i] = "-" + field.Field
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ToSortFields разбирает сортировку вида "price,-start_date": минус перед полем — по убыванию.
+func ToSortFields(value string) ([]models.SortField, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var fields []models.SortField
+	for _, name := range strings.Split(value, ",") {
+		field := models.SortField{Field: strings.TrimSpace(name)}
+		if rest, ok := strings.CutPrefix(field.Field, "-"); ok {
+			field.Field, field.Desc = rest, true
+		}
+
+		if _, ok := models.SortableFields[field.Field]; !ok {
+			return nil, fmt.Errorf("неизвестное поле сортировки: %q", name)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func parseOptionalInt(value, name string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат %s: %w", name, err)
+	}
+	return &n, nil
+}
+
 func ToListSubscriptionsParams(req *TotalSubscriptionsCostRequest) (*models.ListSubscriptionsParams, error) {
 	start, err := time.Parse(layout, req.StartDate)
 	if err != nil {
@@ -68,11 +451,41 @@ func ToListSubscriptionsParams(req *TotalSubscriptionsCostRequest) (*models.List
 		StartDate: start,
 		EndDate:   end,
 	}
+	if err := parseCostFilters(req, model); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// ToForecastParams разбирает фильтры и параметры прогноза. Окно прогноза
+// выбирает сервисный слой от текущего месяца.
+func ToForecastParams(req *TotalSubscriptionsCostRequest, months string) (*models.ListSubscriptionsParams, int, error) {
+	forecastMonths := defaultForecastMonths
+	if months != "" {
+		var err error
+		forecastMonths, err = strconv.Atoi(months)
+		if err != nil {
+			return nil, 0, fmt.Errorf("неверный формат months: %w", err)
+		}
+		if forecastMonths < 1 || forecastMonths > maxForecastMonths {
+			return nil, 0, fmt.Errorf("months должен быть от 1 до %d: %d", maxForecastMonths, forecastMonths)
+		}
+	}
 
+	model := &models.ListSubscriptionsParams{}
+	if err := parseCostFilters(req, model); err != nil {
+		return nil, 0, err
+	}
+	return model, forecastMonths, nil
+}
+
+// parseCostFilters заполняет фильтры подписок и параметры расчёта стоимости.
+func parseCostFilters(req *TotalSubscriptionsCostRequest, model *models.ListSubscriptionsParams) error {
+	var err error
 	if req.UserID != "" {
 		id, err := uuid.Parse(req.UserID)
 		if err != nil {
-			return nil, fmt.Errorf("неверный формат user_id: %w", err)
+			return fmt.Errorf("неверный формат user_id: %w", err)
 		}
 		model.UserID = &id
 	}
@@ -81,5 +494,308 @@ func ToListSubscriptionsParams(req *TotalSubscriptionsCostRequest) (*models.List
 		model.ServiceName = &req.ServiceName
 	}
 
-	return model, nil
+	if req.BillingPeriod != "" {
+		period, err := ToBillingPeriod(req.BillingPeriod)
+		if err != nil {
+			return err
+		}
+		model.BillingPeriod = &period
+	}
+
+	if req.Normalize != "" {
+		model.Options.Normalize, err = strconv.ParseBool(req.Normalize)
+		if err != nil {
+			return fmt.Errorf("неверный формат normalize: %w", err)
+		}
+	}
+
+	model.Options.Currency = models.DefaultCurrency
+	if req.Currency != "" {
+		model.Options.Currency, err = ToCurrency(req.Currency)
+		if err != nil {
+			return err
+		}
+	}
+
+	model.Options.Proration = models.ProrationFull
+	if req.Proration != "" {
+		switch proration := models.Proration(req.Proration); proration {
+		case models.ProrationFull, models.ProrationDaily, models.ProrationRenewal:
+			model.Options.Proration = proration
+		default:
+			return fmt.Errorf("неизвестное значение proration: %q", req.Proration)
+		}
+	}
+	return nil
+}
+
+func ToForecastResponse(costs []models.MonthlyCost, totalCost int, currency string) *ForecastResponse {
+	return &ForecastResponse{
+		Months:    ToMonthlyCostResponse(costs),
+		TotalCost: totalCost,
+		Currency:  currency,
+	}
+}
+
+func ToMonthlyCostResponse(costs []models.MonthlyCost) []MonthlyCostResponse {
+	resp := make([]MonthlyCostResponse, len(costs))
+	for i, cost := range costs {
+		resp[i] = MonthlyCostResponse{
+			Month:    cost.Month.Format(layout),
+			Cost:     cost.Cost,
+			Gross:    cost.Gross,
+			Discount: cost.Discount,
+		}
+	}
+	return resp
+}
+
+func ToCostGroupBy(value string) (models.CostGroupBy, error) {
+	switch groupBy := models.CostGroupBy(value); groupBy {
+	case models.GroupByServiceName, models.GroupByUserID:
+		return groupBy, nil
+	default:
+		return "", fmt.Errorf("неизвестное значение group_by: %q", value)
+	}
+}
+
+func ToGroupedCostResponse(groupBy models.CostGroupBy, groups []models.GroupCost, totalCost int, currency string) *GroupedCostResponse {
+	resp := GroupedCostResponse{
+		GroupBy:   string(groupBy),
+		Groups:    make([]GroupCostResponse, len(groups)),
+		TotalCost: totalCost,
+		Currency:  currency,
+	}
+	for i, group := range groups {
+		resp.Groups[i] = GroupCostResponse{
+			Key:       group.Key,
+			TotalCost: group.Cost,
+		}
+	}
+	return &resp
+}
+
+func ToSubscriptionPrice(req *SubscriptionPriceRequest) (*models.SubscriptionPrice, error) {
+	effectiveFrom, err := time.Parse(layout, req.EffectiveFrom)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат effective_from: %w", err)
+	}
+
+	return &models.SubscriptionPrice{
+		Price:         req.Price,
+		EffectiveFrom: effectiveFrom,
+	}, nil
+}
+
+func ToSubscriptionPriceResponse(price *models.SubscriptionPrice) *SubscriptionPriceResponse {
+	return &SubscriptionPriceResponse{
+		ID:             price.ID,
+		SubscriptionID: price.SubscriptionID,
+		Price:          price.Price,
+		EffectiveFrom:  price.EffectiveFrom.Format(layout),
+	}
+}
+
+func ToSubscriptionDiscount(req *SubscriptionDiscountRequest) (*models.SubscriptionDiscount, error) {
+	kind := models.DiscountKind(req.Kind)
+	switch kind {
+	case models.DiscountPercent:
+		if req.Value <= 0 || req.Value > 100 {
+			return nil, fmt.Errorf("процент скидки должен быть от 1 до 100: %d", req.Value)
+		}
+	case models.DiscountFixed:
+		if req.Value <= 0 {
+			return nil, fmt.Errorf("сумма скидки должна быть положительной: %d", req.Value)
+		}
+	default:
+		return nil, fmt.Errorf("неизвестное значение kind: %q", req.Kind)
+	}
+
+	start, err := time.Parse(layout, req.StartMonth)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат start_month: %w", err)
+	}
+
+	var end *time.Time
+	if req.EndMonth != "" {
+		t, err := time.Parse(layout, req.EndMonth)
+		if err != nil {
+			return nil, fmt.Errorf("неверный формат end_month: %w", err)
+		}
+		if t.Before(start) {
+			return nil, fmt.Errorf("end_month не может быть раньше start_month")
+		}
+		end = &t
+	}
+
+	return &models.SubscriptionDiscount{
+		Kind:       kind,
+		Value:      req.Value,
+		StartMonth: start,
+		EndMonth:   end,
+	}, nil
+}
+
+func ToSubscriptionDiscountResponse(discount *models.SubscriptionDiscount) *SubscriptionDiscountResponse {
+	resp := SubscriptionDiscountResponse{
+		ID:             discount.ID,
+		SubscriptionID: discount.SubscriptionID,
+		Kind:           string(discount.Kind),
+		Value:          discount.Value,
+		StartMonth:     discount.StartMonth.Format(layout),
+	}
+	if discount.EndMonth != nil {
+		resp.EndMonth = discount.EndMonth.Format(layout)
+	}
+	return &resp
+}
+
+func ToSubscriptionPause(req *SubscriptionPauseRequest) (*models.SubscriptionPause, error) {
+	start, err := time.Parse(layout, req.StartMonth)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат start_month: %w", err)
+	}
+
+	end, err := time.Parse(layout, req.EndMonth)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат end_month: %w", err)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end_month не может быть раньше start_month")
+	}
+
+	return &models.SubscriptionPause{
+		StartMonth: start,
+		EndMonth:   &end,
+	}, nil
+}
+
+func ToSubscriptionPauseResponse(pause *models.SubscriptionPause) *SubscriptionPauseResponse {
+	resp := SubscriptionPauseResponse{
+		ID:             pause.ID,
+		SubscriptionID: pause.SubscriptionID,
+		StartMonth:     pause.StartMonth.Format(layout),
+	}
+	if pause.EndMonth != nil {
+		resp.EndMonth = pause.EndMonth.Format(layout)
+	}
+	return &resp
+}
+
+func ToExchangeRate(currency, month string, req *ExchangeRateRequest) (*models.ExchangeRate, error) {
+	currency, err := ToCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := time.Parse(layout, month)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат month: %w", err)
+	}
+
+	if req.Rate <= 0 {
+		return nil, fmt.Errorf("курс должен быть положительным: %v", req.Rate)
+	}
+
+	return &models.ExchangeRate{Currency: currency, Month: m, Rate: req.Rate}, nil
+}
+
+func ToMonth(value string) (time.Time, error) {
+	month, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("неверный формат месяца: %w", err)
+	}
+	return month, nil
+}
+
+func ToExchangeRatesFilter(currency, from, to string) (*models.ExchangeRatesFilter, error) {
+	filter := &models.ExchangeRatesFilter{
+		From: time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(9999, time.December, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if currency != "" {
+		currency, err := ToCurrency(currency)
+		if err != nil {
+			return nil, err
+		}
+		filter.Currencies = []string{currency}
+	}
+
+	var err error
+	if from != "" {
+		if filter.From, err = time.Parse(layout, from); err != nil {
+			return nil, fmt.Errorf("неверный формат from: %w", err)
+		}
+	}
+	if to != "" {
+		if filter.To, err = time.Parse(layout, to); err != nil {
+			return nil, fmt.Errorf("неверный формат to: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+func ToExchangeRateResponse(rate *models.ExchangeRate) *ExchangeRateResponse {
+	return &ExchangeRateResponse{
+		Currency: rate.Currency,
+		Month:    rate.Month.Format(layout),
+		Rate:     rate.Rate,
+	}
+}
+
+func ToMissingRates(err *models.MissingRatesError) []MissingRateResponse {
+	missing := make([]MissingRateResponse, len(err.Missing))
+	for i, m := range err.Missing {
+		missing[i] = MissingRateResponse{
+			Currency: m.Currency,
+			Month:    m.Month.Format(layout),
+		}
+	}
+	return missing
+}
+
+// ToBatchMode возвращает true для режима «всё или ничего».
+func ToBatchMode(mode string) (bool, error) {
+	switch mode {
+	case "", "atomic":
+		return true, nil
+	case "partial":
+		return false, nil
+	default:
+		return false, fmt.Errorf("неверный режим пакета: %q", mode)
+	}
+}
+
+// ToBatchOperation проверяет операцию пакета теми же правилами, что и одиночные
+// ручки. Нарушения в данных подписки имеют поля с префиксом "subscription.".
+func ToBatchOperation(req *BatchOperationRequest) (models.BatchOperation, error) {
+	op := models.BatchOperation{Kind: models.BatchOperationKind(req.Op)}
+	v := &validator{}
+	switch op.Kind {
+	case models.BatchCreate:
+		if req.ID != 0 {
+			v.add("id", CodeInvalidValue, "%s must not be set for %s", req.Op)
+		}
+	case models.BatchUpdate, models.BatchDelete:
+		if req.ID <= 0 {
+			v.add("id", CodeRequired, "%s is required for %s", req.Op)
+		}
+	default:
+		v.add("op", CodeInvalidValue, "%s must be one of %s", "create, update, delete")
+		return op, v.err()
+	}
+
+	switch {
+	case op.Kind == models.BatchDelete:
+		op.Subscription = &models.Subscription{ID: req.ID, Version: req.Version}
+	case req.Subscription == nil:
+		v.add("subscription", CodeRequired, "%s is required for %s", req.Op)
+	default:
+		v.prefix = "subscription."
+		sub := toSubscription(req.Subscription, v)
+		sub.ID, sub.Version = req.ID, req.Version
+		op.Subscription = sub
+	}
+	return op, v.err()
 }
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestToCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{"valid", `{"sort":"-end_date,price,user_id,id","values":["infinity","542","550e8400-e29b-41d4-a716-446655440000","7"]}`, false},
+		{"valid dates", `{"sort":"start_date,service_name,id","values":["2025-07-01","Netflix","3"]}`, false},
+		{"id is not a number", `{"sort":"id","values":["abc"]}`, true},
+		{"price out of int range", `{"sort":"price,id","values":["99999999999","1"]}`, true},
+		{"start_date is not a date", `{"sort":"start_date,id","values":["infinity","1"]}`, true},
+		{"user_id is not a uuid", `{"sort":"user_id,id","values":["42","1"]}`, true},
+		{"service_name with NUL", `{"sort":"service_name,id","values":["a\u0000b","1"]}`, true},
+		{"values count mismatch", `{"sort":"price,id","values":["542"]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ToCursor(base64.RawURLEncoding.EncodeToString([]byte(tt.payload)))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToCursor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
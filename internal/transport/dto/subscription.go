@@ -1,25 +1,197 @@
 package dto
 
 type SubscriptionRequest struct {
-	ServiceName string `json:"service_name"`
-	Price       int    `json:"price"`
-	UserID      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date,omitempty"`
+	ServiceName   string `json:"service_name"`
+	Price         int    `json:"price"`
+	UserID        string `json:"user_id"`
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date,omitempty"`
+	BillingPeriod string `json:"billing_period,omitempty" enums:"weekly,monthly,quarterly,yearly" default:"monthly"`
+	Currency      string `json:"currency,omitempty" default:"RUB" example:"USD"`
+	TrialEndDate  string `json:"trial_end_date,omitempty" example:"08-2025"`
 }
 
 type SubscriptionResponse struct {
-	ID          int    `json:"id"`
-	ServiceName string `json:"service_name"`
-	Price       int    `json:"price"`
-	UserID      string `json:"user_id"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date,omitempty"`
+	ID            int    `json:"id"`
+	ServiceName   string `json:"service_name"`
+	Price         int    `json:"price"`
+	UserID        string `json:"user_id"`
+	StartDate     string `json:"start_date"`
+	EndDate       string `json:"end_date,omitempty"`
+	BillingPeriod string `json:"billing_period"`
+	Currency      string `json:"currency"`
+	TrialEndDate  string `json:"trial_end_date,omitempty"`
+	Status        string `json:"status" enums:"active,paused,cancelled,expired"`
+}
+
+type SubscriptionListRequest struct {
+	UserID            string
+	ServiceName       string
+	ServiceNamePrefix string
+	MinPrice          string
+	MaxPrice          string
+	ActiveOn          string
+	OpenEnded         string
+	Sort              string
+	Cursor            string
+}
+
+// SubscriptionListResponse — страница подписок с общим числом записей под фильтрами.
+// Next — ссылка на следующую страницу или null, если страница последняя.
+// NextCursor заполняется только при курсорной пагинации, Offset в ней всегда 0.
+type SubscriptionListResponse struct {
+	Items      []SubscriptionResponse `json:"items"`
+	Total      int                    `json:"total"`
+	Limit      int                    `json:"limit"`
+	Offset     int                    `json:"offset"`
+	Next       *string                `json:"next"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// cursorPayload — содержимое непрозрачного курсора до кодирования в base64.
+type cursorPayload struct {
+	Sort   string   `json:"sort"`
+	Values []string `json:"values"`
 }
 
 type TotalSubscriptionsCostRequest struct {
-	ServiceName string
-	UserID      string
-	StartDate   string
-	EndDate     string
+	ServiceName   string
+	UserID        string
+	StartDate     string
+	EndDate       string
+	BillingPeriod string
+	Normalize     string
+	Proration     string
+	Currency      string
+}
+
+type TotalCostResponse struct {
+	TotalCost int    `json:"totalCost"`
+	Currency  string `json:"currency"`
+}
+
+type MonthlyCostResponse struct {
+	Month    string `json:"month"`
+	Cost     int    `json:"cost"`
+	Gross    int    `json:"gross"`
+	Discount int    `json:"discount"`
+}
+
+type ForecastResponse struct {
+	Months    []MonthlyCostResponse `json:"months"`
+	TotalCost int                   `json:"totalCost"`
+	Currency  string                `json:"currency"`
+}
+
+type GroupCostResponse struct {
+	Key       string `json:"key"`
+	TotalCost int    `json:"totalCost"`
+}
+
+type GroupedCostResponse struct {
+	GroupBy   string              `json:"groupBy"`
+	Groups    []GroupCostResponse `json:"groups"`
+	TotalCost int                 `json:"totalCost"`
+	Currency  string              `json:"currency"`
+}
+
+type SubscriptionPriceRequest struct {
+	Price         int    `json:"price"`
+	EffectiveFrom string `json:"effective_from"`
+}
+
+type SubscriptionPriceResponse struct {
+	ID             int    `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	Price          int    `json:"price"`
+	EffectiveFrom  string `json:"effective_from"`
+}
+
+type SubscriptionDiscountRequest struct {
+	Kind       string `json:"kind" enums:"percent,fixed"`
+	Value      int    `json:"value" example:"50"`
+	StartMonth string `json:"start_month" example:"07-2025"`
+	EndMonth   string `json:"end_month,omitempty" example:"09-2025"`
+}
+
+type SubscriptionDiscountResponse struct {
+	ID             int    `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	Kind           string `json:"kind"`
+	Value          int    `json:"value"`
+	StartMonth     string `json:"start_month"`
+	EndMonth       string `json:"end_month,omitempty"`
+}
+
+type SubscriptionPauseRequest struct {
+	StartMonth string `json:"start_month" example:"07-2025"`
+	EndMonth   string `json:"end_month" example:"08-2025"`
+}
+
+type SubscriptionPauseResponse struct {
+	ID             int    `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	StartMonth     string `json:"start_month"`
+	EndMonth       string `json:"end_month,omitempty"`
+}
+
+type ExchangeRateRequest struct {
+	Rate float64 `json:"rate" example:"92.5"`
+}
+
+type ExchangeRateResponse struct {
+	Currency string  `json:"currency"`
+	Month    string  `json:"month"`
+	Rate     float64 `json:"rate"`
+}
+
+type MissingRateResponse struct {
+	Currency string `json:"currency"`
+	Month    string `json:"month"`
+}
+
+// Problem — описание ошибки в формате RFC 7807 (application/problem+json).
+// Code — стабильный код доменной ошибки, Errors заполняется для ошибок валидации,
+// MissingRates — при нехватке курсов валют.
+type Problem struct {
+	Type         string                `json:"type" example:"/problems/subscription-not-found"`
+	Title        string                `json:"title" example:"Subscription not found"`
+	Status       int                   `json:"status" example:"404"`
+	Detail       string                `json:"detail,omitempty" example:"subscription 42 not found"`
+	Instance     string                `json:"instance,omitempty" example:"/subscriptions/42"`
+	RequestID    string                `json:"request_id,omitempty"`
+	Code         string                `json:"code,omitempty" example:"SUBSCRIPTION_NOT_FOUND"`
+	Errors       []FieldError          `json:"errors,omitempty"`
+	MissingRates []MissingRateResponse `json:"missing_rates,omitempty"`
+}
+
+// BatchRequest — пакет операций. Mode atomic (по умолчанию) — всё или ничего,
+// partial — каждая операция применяется независимо.
+type BatchRequest struct {
+	Mode       string                  `json:"mode,omitempty" enums:"atomic,partial" default:"atomic"`
+	Operations []BatchOperationRequest `json:"operations"`
+}
+
+// BatchOperationRequest — операция пакета: create требует subscription, update —
+// id и subscription, delete — id. Version — необязательная ожидаемая версия (ETag).
+type BatchOperationRequest struct {
+	Op           string               `json:"op" enums:"create,update,delete"`
+	ID           int                  `json:"id,omitempty"`
+	Version      int                  `json:"version,omitempty"`
+	Subscription *SubscriptionRequest `json:"subscription,omitempty"`
+}
+
+type BatchResponse struct {
+	Applied bool                  `json:"applied"`
+	Results []BatchResultResponse `json:"results"`
+}
+
+// BatchResultResponse — результат операции с HTTP-статусом, который вернула бы
+// одиночная ручка. 424 — операция отменена из-за ошибки другой операции пакета.
+type BatchResultResponse struct {
+	Index        int                   `json:"index"`
+	Status       int                   `json:"status"`
+	Subscription *SubscriptionResponse `json:"subscription,omitempty"`
+	Error        string                `json:"error,omitempty"`
+	Errors       []FieldError          `json:"errors,omitempty"`
 }
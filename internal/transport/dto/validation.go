@@ -0,0 +1,102 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AntonTsoy/subscription-service/internal/i18n"
+)
+
+// Коды нарушений в FieldError.
+const (
+	CodeRequired       = "required"
+	CodeInvalidFormat  = "invalid_format"
+	CodeInvalidType    = "invalid_type"
+	CodeInvalidValue   = "invalid_value"
+	CodeMin            = "min"
+	CodeFormatMismatch = "format_mismatch"
+	CodeDateOrder      = "date_order"
+	CodeUnknownField   = "unknown_field"
+)
+
+// FieldError — нарушение правила валидации одного поля запроса.
+type FieldError struct {
+	Field   string `json:"field" example:"price"`
+	Code    string `json:"code" example:"min"`
+	Message string `json:"message" example:"price must not be negative"`
+}
+
+// ValidationError — все нарушения, найденные в запросе. Отдаётся клиенту с 422.
+// Сообщения в Errors — на английском, Localized переводит их на язык клиента.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+
+	// messages[i] — шаблон сообщения Errors[i] для перевода.
+	messages []message
+}
+
+type message struct {
+	format string
+	args   []any
+}
+
+// Localized возвращает нарушения с сообщениями на языке lang.
+func (e *ValidationError) Localized(lang i18n.Lang) []FieldError {
+	errs := make([]FieldError, len(e.Errors))
+	copy(errs, e.Errors)
+	for i, m := range e.messages {
+		errs[i].Message = i18n.Sprintf(lang, m.format, m.args...)
+	}
+	return errs
+}
+
+// Error описывает нарушения по-русски, как и остальные сообщения в логах,
+// независимо от языка клиента.
+func (e *ValidationError) Error() string {
+	errs := e.Localized(i18n.Russian)
+	violations := make([]string, len(errs))
+	for i, fieldErr := range errs {
+		violations[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return "ошибка валидации: " + strings.Join(violations, "; ")
+}
+
+// validator собирает нарушения, prefix добавляется к именам полей
+// (например, "subscription." для операций пакета). Первый аргумент шаблона
+// сообщения — имя поля без префикса.
+type validator struct {
+	prefix   string
+	errors   []FieldError
+	messages []message
+}
+
+func (v *validator) add(field, code, format string, args ...any) {
+	args = append([]any{field}, args...)
+	v.errors = append(v.errors, FieldError{Field: v.prefix + field, Code: code, Message: fmt.Sprintf(format, args...)})
+	v.messages = append(v.messages, message{format: format, args: args})
+}
+
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.validationError()
+}
+
+func (v *validator) validationError() *ValidationError {
+	return &ValidationError{Errors: v.errors, messages: v.messages}
+}
+
+// DecodeValidationError превращает ошибку типа поля при разборе JSON в ValidationError.
+// Для остальных ошибок (синтаксис JSON и т.п.) возвращается nil.
+func DecodeValidationError(err error) *ValidationError {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		return nil
+	}
+	v := &validator{}
+	v.add(typeErr.Field, CodeInvalidType, "%s must be %s", typeErr.Type.String())
+	return v.validationError()
+}
@@ -0,0 +1,128 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AntonTsoy/subscription-service/internal/i18n"
+)
+
+func TestToSubscriptionValidation(t *testing.T) {
+	valid := SubscriptionRequest{
+		ServiceName: "Netflix", Price: 542, UserID: "550e8400-e29b-41d4-a716-446655440000", StartDate: "07-2025",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *SubscriptionRequest)
+		want   []FieldError
+	}{
+		{"valid", func(req *SubscriptionRequest) {}, nil},
+		{
+			name: "collects every violation",
+			modify: func(req *SubscriptionRequest) {
+				req.ServiceName, req.Price, req.UserID, req.Currency = " ", -1, "", "usd"
+			},
+			want: []FieldError{
+				{"service_name", CodeRequired, "service_name is required"},
+				{"price", CodeMin, "price must not be negative"},
+				{"user_id", CodeRequired, "user_id is required"},
+				{"currency", CodeInvalidValue, "currency must be an ISO 4217 code like USD"},
+			},
+		},
+		{
+			name:   "end before start",
+			modify: func(req *SubscriptionRequest) { req.EndDate = "06-2025" },
+			want:   []FieldError{{"end_date", CodeDateOrder, "end_date must not be before start_date"}},
+		},
+		{
+			name:   "trial after end",
+			modify: func(req *SubscriptionRequest) { req.EndDate, req.TrialEndDate = "08-2025", "09-2025" },
+			want:   []FieldError{{"trial_end_date", CodeDateOrder, "trial_end_date must not be after end_date"}},
+		},
+		{
+			name:   "dates in different formats",
+			modify: func(req *SubscriptionRequest) { req.TrialEndDate = "2025-08-01" },
+			want:   []FieldError{{"trial_end_date", CodeFormatMismatch, "trial_end_date must use the same format as start_date"}},
+		},
+		{
+			name:   "invalid start skips date order checks",
+			modify: func(req *SubscriptionRequest) { req.StartDate, req.EndDate = "2025/07", "06-2025" },
+			want:   []FieldError{{"start_date", CodeInvalidFormat, "start_date must be MM-YYYY or YYYY-MM-DD"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+
+			_, err := ToSubscription(&req)
+			var verr *ValidationError
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ToSubscription() error = %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &verr) {
+				t.Fatalf("ToSubscription() error = %v, want *ValidationError", err)
+			}
+			if !reflect.DeepEqual(verr.Errors, tt.want) {
+				t.Errorf("ToSubscription() errors = %+v, want %+v", verr.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestToBatchOperationPrefixesFields(t *testing.T) {
+	_, err := ToBatchOperation(&BatchOperationRequest{Op: "update", Subscription: &SubscriptionRequest{
+		ServiceName: "Okko", UserID: "550e8400-e29b-41d4-a716-446655440000", StartDate: "01-2025", Price: -5,
+	}})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ToBatchOperation() error = %v, want *ValidationError", err)
+	}
+	want := []FieldError{
+		{"id", CodeRequired, "id is required for update"},
+		{"subscription.price", CodeMin, "price must not be negative"},
+	}
+	if !reflect.DeepEqual(verr.Errors, want) {
+		t.Errorf("ToBatchOperation() errors = %+v, want %+v", verr.Errors, want)
+	}
+}
+
+func TestValidationErrorLocalized(t *testing.T) {
+	_, err := ToSubscription(&SubscriptionRequest{ServiceName: "Okko", UserID: "not-a-uuid", StartDate: "01-2025", BillingPeriod: "daily"})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ToSubscription() error = %v, want *ValidationError", err)
+	}
+	want := []FieldError{
+		{"user_id", CodeInvalidFormat, "поле user_id должно быть UUID"},
+		{"billing_period", CodeInvalidValue, "поле billing_period должно принимать одно из значений: weekly, monthly, quarterly, yearly"},
+	}
+	if got := verr.Localized(i18n.Russian); !reflect.DeepEqual(got, want) {
+		t.Errorf("Localized(ru) = %+v, want %+v", got, want)
+	}
+	if verr.Errors[0].Message != "user_id must be a UUID" {
+		t.Errorf("Localized() changed the original errors: %+v", verr.Errors)
+	}
+}
+
+func TestDecodeValidationError(t *testing.T) {
+	var req SubscriptionRequest
+	err := json.Unmarshal([]byte(`{"price": "free"}`), &req)
+
+	verr := DecodeValidationError(err)
+	if verr == nil || len(verr.Errors) != 1 || verr.Errors[0].Field != "price" || verr.Errors[0].Code != CodeInvalidType {
+		t.Errorf("DecodeValidationError() = %+v, want invalid_type for price", verr)
+	}
+	if DecodeValidationError(json.Unmarshal([]byte(`{`), &req)) != nil {
+		t.Error("DecodeValidationError() for syntax error: want nil")
+	}
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AntonTsoy/subscription-service/internal/i18n"
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+// BatchSubscriptions godoc
+// @Summary      Пакетные операции над подписками
+// @Description  Выполняет до 1000 операций create/update/delete в одной транзакции.
+// @Description  В режиме atomic (по умолчанию) ошибка любой операции отменяет весь пакет: ответ получает статус упавшей операции, остальные операции — 424.
+// @Description  В режиме partial каждая операция применяется независимо, ответ — 200 со статусом каждой операции.
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key header string false "Ключ идемпотентности: повтор с тем же ключом вернёт сохранённый ответ"
+// @Param        request body dto.BatchRequest true "Режим и операции пакета"
+// @Success      200 {object} dto.BatchResponse "Результаты операций"
+// @Failure      400 {object} dto.Problem "Некорректный пакет"
+// @Failure      404 {object} dto.BatchResponse "Пакет отменён: подписка не найдена"
+// @Failure      412 {object} dto.BatchResponse "Пакет отменён: версия подписки не совпадает"
+// @Failure      422 {object} dto.BatchResponse "Пакет отменён: операция не прошла валидацию (нарушения в results[].errors)"
+// @Failure      500 {object} dto.Problem "Ошибка при выполнении пакета"
+// @Router       /subscriptions/batch [post]
+func (h *SubsHandler) BatchSubscriptions(w http.ResponseWriter, r *http.Request) {
+	var req dto.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if writeValidationError(w, r, err) {
+			return
+		}
+		logger.FromContext(r.Context()).Warn("неправильное тело пакетного запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	atomic, err := dto.ToBatchMode(req.Mode)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный режим пакета", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid batch mode")
+		return
+	}
+	if len(req.Operations) == 0 || len(req.Operations) > dto.MaxBatchOperations {
+		logger.FromContext(r.Context()).Warn("неправильное число операций в пакете", "operations", len(req.Operations))
+		problem.Write(w, r, http.StatusBadRequest, "batch must contain from 1 to 1000 operations")
+		return
+	}
+
+	lang := i18n.FromRequest(r)
+	resp := dto.BatchResponse{Results: make([]dto.BatchResultResponse, len(req.Operations))}
+	var ops []models.BatchOperation
+	var indexes []int
+	for i := range req.Operations {
+		op, err := dto.ToBatchOperation(&req.Operations[i])
+		if err != nil {
+			logger.FromContext(r.Context()).Warn("неправильная операция пакета", "index", i, "error", err)
+			resp.Results[i] = dto.BatchResultResponse{Index: i, Status: http.StatusUnprocessableEntity, Error: i18n.Text(lang, "invalid operation")}
+			var verr *dto.ValidationError
+			if errors.As(err, &verr) {
+				resp.Results[i].Errors = verr.Localized(lang)
+			}
+			continue
+		}
+		ops = append(ops, op)
+		indexes = append(indexes, i)
+	}
+
+	if atomic && len(ops) < len(req.Operations) {
+		writeBatchResponse(w, http.StatusUnprocessableEntity, rollBackResults(&resp, lang))
+		return
+	}
+
+	results, err := h.service.Batch(r.Context(), ops, atomic)
+	if err != nil && !errors.Is(err, models.ErrBatchRolledBack) {
+		logError(r, "ошибка выполнения пакета", err)
+		problem.WriteError(w, r, err, "failed to apply batch")
+		return
+	}
+
+	// В отменённом пакете статус ответа — статус упавшей операции.
+	status := http.StatusOK
+	for j, result := range results {
+		i := indexes[j]
+		switch {
+		case result.Err != nil:
+			logError(r, "ошибка операции пакета", result.Err, "index", i)
+			resp.Results[i] = batchErrorResult(i, result.Err, lang)
+			status = resp.Results[i].Status
+		case err == nil:
+			resp.Results[i] = batchSuccessResult(i, ops[j].Kind, result.Subscription)
+		}
+	}
+
+	if err != nil {
+		writeBatchResponse(w, status, rollBackResults(&resp, lang))
+		return
+	}
+	resp.Applied = true
+	writeBatchResponse(w, http.StatusOK, &resp)
+}
+
+func batchSuccessResult(index int, kind models.BatchOperationKind, sub *models.Subscription) dto.BatchResultResponse {
+	switch kind {
+	case models.BatchCreate:
+		return dto.BatchResultResponse{Index: index, Status: http.StatusCreated, Subscription: dto.ToSubscriptionResponse(sub)}
+	case models.BatchDelete:
+		return dto.BatchResultResponse{Index: index, Status: http.StatusNoContent}
+	default:
+		return dto.BatchResultResponse{Index: index, Status: http.StatusOK, Subscription: dto.ToSubscriptionResponse(sub)}
+	}
+}
+
+func batchErrorResult(index int, err error, lang i18n.Lang) dto.BatchResultResponse {
+	status, title := problem.Status(err, lang)
+	return dto.BatchResultResponse{Index: index, Status: status, Error: title}
+}
+
+// rollBackResults помечает успешные операции отменённого пакета статусом 424.
+func rollBackResults(resp *dto.BatchResponse, lang i18n.Lang) *dto.BatchResponse {
+	for i, result := range resp.Results {
+		if result.Error == "" {
+			resp.Results[i] = dto.BatchResultResponse{Index: i, Status: http.StatusFailedDependency, Error: i18n.Text(lang, "rolled back")}
+		}
+	}
+	return resp
+}
+
+func writeBatchResponse(w http.ResponseWriter, status int, resp *dto.BatchResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+// AddSubscriptionDiscount godoc
+// @Summary      Добавить скидку на подписку
+// @Description  Добавляет процентную или фиксированную скидку, действующую с месяца start_month по end_month включительно
+// @Tags         discounts
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Param        request body dto.SubscriptionDiscountRequest true "Тип, размер и месяцы действия скидки (MM-YYYY)"
+// @Success      201 {object} dto.SubscriptionDiscountResponse "Добавленная скидка"
+// @Failure      400 {object} dto.Problem "Некорректные данные запроса"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      422 {object} dto.Problem "Скидка начинается вне периода подписки"
+// @Failure      500 {object} dto.Problem "Ошибка при добавлении скидки"
+// @Router       /subscriptions/{id}/discounts [post]
+func (h *SubsHandler) AddSubscriptionDiscount(w http.ResponseWriter, r *http.Request) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	var req dto.SubscriptionDiscountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromContext(r.Context()).Warn("неправильное тело запроса для добавления скидки", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	discount, err := dto.ToSubscriptionDiscount(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр тела запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request body parameter")
+		return
+	}
+	discount.SubscriptionID = subID
+
+	if err := h.service.AddDiscount(r.Context(), discount); err != nil {
+		logError(r, "ошибка добавления скидки", err)
+		problem.WriteError(w, r, err, "failed to add subscription discount")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.ToSubscriptionDiscountResponse(discount))
+}
+
+// GetSubscriptionDiscounts godoc
+// @Summary      Скидки подписки
+// @Description  Возвращает все скидки подписки в порядке начала действия
+// @Tags         discounts
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Success      200 {array} dto.SubscriptionDiscountResponse "Скидки подписки"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      500 {object} dto.Problem "Ошибка при получении скидок"
+// @Router       /subscriptions/{id}/discounts [get]
+func (h *SubsHandler) GetSubscriptionDiscounts(w http.ResponseWriter, r *http.Request) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	discounts, err := h.service.ListDiscounts(r.Context(), subID)
+	if err != nil {
+		logError(r, "ошибка получения скидок", err)
+		problem.WriteError(w, r, err, "failed to get subscription discounts")
+		return
+	}
+
+	response := make([]dto.SubscriptionDiscountResponse, len(discounts))
+	for i, discount := range discounts {
+		response[i] = *dto.ToSubscriptionDiscountResponse(&discount)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+	"github.com/go-chi/chi/v5"
+)
+
+// SaveExchangeRate godoc
+// @Summary      Задать курс валюты
+// @Description  Создаёт или заменяет курс валюты к рублю на месяц
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Param        currency path string true "Код валюты ISO 4217"
+// @Param        month path string true "Месяц действия курса (MM-YYYY)"
+// @Param        request body dto.ExchangeRateRequest true "Курс: сколько рублей стоит единица валюты"
+// @Security     AdminToken
+// @Success      200 {object} dto.ExchangeRateResponse "Сохранённый курс"
+// @Failure      400 {object} dto.Problem "Некорректные данные запроса"
+// @Failure      401 {object} dto.Problem "Нет токена администратора или он неверный"
+// @Failure      500 {object} dto.Problem "Ошибка при сохранении курса"
+// @Router       /admin/exchange-rates/{currency}/{month} [put]
+func (h *SubsHandler) SaveExchangeRate(w http.ResponseWriter, r *http.Request) {
+	var req dto.ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromContext(r.Context()).Warn("неправильное тело запроса для сохранения курса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	rate, err := dto.ToExchangeRate(chi.URLParam(r, "currency"), chi.URLParam(r, "month"), &req)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр запроса курса валюты", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid exchange rate parameter")
+		return
+	}
+
+	if err := h.service.SaveExchangeRate(r.Context(), rate); err != nil {
+		logError(r, "ошибка сохранения курса валюты", err)
+		problem.WriteError(w, r, err, "failed to save exchange rate")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ToExchangeRateResponse(rate))
+}
+
+// GetExchangeRates godoc
+// @Summary      Список курсов валют
+// @Description  Возвращает курсы валют к рублю с фильтрацией по валюте и периоду
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Param        currency query string false "Код валюты ISO 4217 (опционально)"
+// @Param        from query string false "Первый месяц (MM-YYYY, опционально)"
+// @Param        to query string false "Последний месяц (MM-YYYY, опционально)"
+// @Success      200 {array} dto.ExchangeRateResponse "Курсы валют"
+// @Failure      400 {object} dto.Problem "Некорректные параметры запроса"
+// @Failure      500 {object} dto.Problem "Ошибка при получении курсов"
+// @Router       /admin/exchange-rates [get]
+func (h *SubsHandler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := dto.ToExchangeRatesFilter(query.Get("currency"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр запроса курсов валют", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid exchange rates query parameter")
+		return
+	}
+
+	rates, err := h.service.ListExchangeRates(r.Context(), filter)
+	if err != nil {
+		logError(r, "ошибка получения курсов валют", err)
+		problem.WriteError(w, r, err, "failed to get exchange rates")
+		return
+	}
+
+	response := make([]dto.ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		response[i] = *dto.ToExchangeRateResponse(&rate)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteExchangeRate godoc
+// @Summary      Удалить курс валюты
+// @Description  Удаляет курс валюты на месяц
+// @Tags         exchange-rates
+// @Accept       json
+// @Produce      json
+// @Param        currency path string true "Код валюты ISO 4217"
+// @Param        month path string true "Месяц действия курса (MM-YYYY)"
+// @Security     AdminToken
+// @Success      204 "Курс успешно удалён"
+// @Failure      400 {object} dto.Problem "Некорректные параметры запроса"
+// @Failure      401 {object} dto.Problem "Нет токена администратора или он неверный"
+// @Failure      404 {object} dto.Problem "Курс не найден"
+// @Failure      500 {object} dto.Problem "Ошибка при удалении курса"
+// @Router       /admin/exchange-rates/{currency}/{month} [delete]
+func (h *SubsHandler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	currency, err := dto.ToCurrency(chi.URLParam(r, "currency"))
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный код валюты", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid currency path parameter")
+		return
+	}
+
+	month, err := dto.ToMonth(chi.URLParam(r, "month"))
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный месяц курса валюты", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid month path parameter")
+		return
+	}
+
+	if err := h.service.DeleteExchangeRate(r.Context(), currency, month); err != nil {
+		logError(r, "ошибка удаления курса валюты", err)
+		problem.WriteError(w, r, err, "failed to delete exchange rate")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
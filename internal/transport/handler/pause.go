@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+// AddSubscriptionPause godoc
+// @Summary      Добавить паузу подписки
+// @Description  Замораживает подписку на месяцы [start_month; end_month], они не оплачиваются
+// @Description  Пауза должна попадать в период подписки и не пересекаться с другими паузами
+// @Tags         pauses
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Param        request body dto.SubscriptionPauseRequest true "Первый и последний месяц паузы (MM-YYYY)"
+// @Success      201 {object} dto.SubscriptionPauseResponse "Добавленная пауза"
+// @Failure      400 {object} dto.Problem "Некорректные данные запроса"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      409 {object} dto.Problem "Пауза пересекается с другой паузой"
+// @Failure      422 {object} dto.Problem "Пауза вне периода подписки"
+// @Failure      500 {object} dto.Problem "Ошибка при добавлении паузы"
+// @Router       /subscriptions/{id}/pauses [post]
+func (h *SubsHandler) AddSubscriptionPause(w http.ResponseWriter, r *http.Request) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	var req dto.SubscriptionPauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromContext(r.Context()).Warn("неправильное тело запроса для добавления паузы", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	pause, err := dto.ToSubscriptionPause(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр тела запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request body parameter")
+		return
+	}
+	pause.SubscriptionID = subID
+
+	if err := h.service.AddPause(r.Context(), pause); err != nil {
+		logError(r, "ошибка добавления паузы", err)
+		problem.WriteError(w, r, err, "failed to add subscription pause")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.ToSubscriptionPauseResponse(pause))
+}
+
+// GetSubscriptionPauses godoc
+// @Summary      Паузы подписки
+// @Description  Возвращает все паузы подписки в порядке начала. У текущей паузы нет end_month
+// @Tags         pauses
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Success      200 {array} dto.SubscriptionPauseResponse "Паузы подписки"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      500 {object} dto.Problem "Ошибка при получении пауз"
+// @Router       /subscriptions/{id}/pauses [get]
+func (h *SubsHandler) GetSubscriptionPauses(w http.ResponseWriter, r *http.Request) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	pauses, err := h.service.ListPauses(r.Context(), subID)
+	if err != nil {
+		logError(r, "ошибка получения пауз", err)
+		problem.WriteError(w, r, err, "failed to get subscription pauses")
+		return
+	}
+
+	response := make([]dto.SubscriptionPauseResponse, len(pauses))
+	for i, pause := range pauses {
+		response[i] = *dto.ToSubscriptionPauseResponse(&pause)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteSubscriptionPause godoc
+// @Summary      Удалить паузу подписки
+// @Description  Удаляет паузу с end_month. Текущая пауза без end_month снимается через resume
+// @Tags         pauses
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Param        pauseId path int true "ID паузы"
+// @Success      204 "Пауза удалена"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Пауза не найдена"
+// @Failure      409 {object} dto.Problem "Пауза ещё не закончилась"
+// @Failure      500 {object} dto.Problem "Ошибка при удалении паузы"
+// @Router       /subscriptions/{id}/pauses/{pauseId} [delete]
+func (h *SubsHandler) DeleteSubscriptionPause(w http.ResponseWriter, r *http.Request) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	pauseID, err := getIntPathParam(r, "pauseId")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id паузы в пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid pause id path parameter value")
+		return
+	}
+
+	if err := h.service.DeletePause(r.Context(), subID, pauseID); err != nil {
+		logError(r, "ошибка удаления паузы", err)
+		problem.WriteError(w, r, err, "failed to delete subscription pause")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
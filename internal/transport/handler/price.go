@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+// AddSubscriptionPrice godoc
+// @Summary      Изменить цену подписки
+// @Description  Добавляет новую цену подписки, действующую начиная с месяца effective_from. Прошлые месяцы считаются по прежней цене. Цена на уже заданный месяц заменяется
+// @Tags         prices
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Param        request body dto.SubscriptionPriceRequest true "Новая цена и месяц начала её действия (MM-YYYY)"
+// @Success      201 {object} dto.SubscriptionPriceResponse "Добавленное изменение цены"
+// @Success      200 {object} dto.SubscriptionPriceResponse "Цена на этот месяц заменена"
+// @Failure      400 {object} dto.Problem "Некорректные данные запроса"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      422 {object} dto.Problem "Месяц изменения цены вне периода подписки"
+// @Failure      500 {object} dto.Problem "Ошибка при изменении цены"
+// @Router       /subscriptions/{id}/prices [post]
+func (h *SubsHandler) AddSubscriptionPrice(w http.ResponseWriter, r *http.Request) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	var req dto.SubscriptionPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.FromContext(r.Context()).Warn("неправильное тело запроса для изменения цены", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	price, err := dto.ToSubscriptionPrice(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр тела запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request body parameter")
+		return
+	}
+	price.SubscriptionID = subID
+
+	replaced, err := h.service.AddPrice(r.Context(), price)
+	if err != nil {
+		logError(r, "ошибка изменения цены подписки", err)
+		problem.WriteError(w, r, err, "failed to add subscription price")
+		return
+	}
+
+	status := http.StatusCreated
+	if replaced {
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(dto.ToSubscriptionPriceResponse(price))
+}
+
+// GetSubscriptionPrices godoc
+// @Summary      История цен подписки
+// @Description  Возвращает все изменения цены подписки в порядке вступления в силу
+// @Tags         prices
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Success      200 {array} dto.SubscriptionPriceResponse "История цен"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      500 {object} dto.Problem "Ошибка при получении истории цен"
+// @Router       /subscriptions/{id}/prices [get]
+func (h *SubsHandler) GetSubscriptionPrices(w http.ResponseWriter, r *http.Request) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	prices, err := h.service.ListPrices(r.Context(), subID)
+	if err != nil {
+		logError(r, "ошибка получения истории цен", err)
+		problem.WriteError(w, r, err, "failed to get subscription prices")
+		return
+	}
+
+	response := make([]dto.SubscriptionPriceResponse, len(prices))
+	for i, price := range prices {
+		response[i] = *dto.ToSubscriptionPriceResponse(&price)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+// PauseSubscription godoc
+// @Summary      Приостановить подписку
+// @Description  Переводит действующую подписку в статус paused. Месяцы со следующего и до возобновления не оплачиваются
+// @Tags         status
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Success      200 {object} dto.SubscriptionResponse "Подписка после смены статуса"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      409 {object} dto.Problem "Недопустимая смена статуса или пересечение с другой паузой"
+// @Failure      500 {object} dto.Problem "Ошибка при смене статуса"
+// @Router       /subscriptions/{id}/pause [post]
+func (h *SubsHandler) PauseSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.Pause)
+}
+
+// ResumeSubscription godoc
+// @Summary      Возобновить подписку
+// @Description  Возвращает приостановленную подписку в статус active, оплата возобновляется с текущего месяца
+// @Tags         status
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Success      200 {object} dto.SubscriptionResponse "Подписка после смены статуса"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      409 {object} dto.Problem "Недопустимая смена статуса"
+// @Failure      500 {object} dto.Problem "Ошибка при смене статуса"
+// @Router       /subscriptions/{id}/resume [post]
+func (h *SubsHandler) ResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.Resume)
+}
+
+// CancelSubscription godoc
+// @Summary      Отменить подписку
+// @Description  Переводит действующую или приостановленную подписку в статус cancelled, end_date переносится на текущий день (месяц)
+// @Tags         status
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Success      200 {object} dto.SubscriptionResponse "Подписка после смены статуса"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      409 {object} dto.Problem "Недопустимая смена статуса"
+// @Failure      500 {object} dto.Problem "Ошибка при смене статуса"
+// @Router       /subscriptions/{id}/cancel [post]
+func (h *SubsHandler) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.service.Cancel)
+}
+
+func (h *SubsHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id int) (*models.Subscription, error)) {
+	subID, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	sub, err := change(r.Context(), subID)
+	if err != nil {
+		logError(r, "ошибка смены статуса подписки", err)
+		problem.WriteError(w, r, err, "failed to change subscription status")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ToSubscriptionResponse(sub))
+}
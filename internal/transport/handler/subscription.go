@@ -5,22 +5,48 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/AntonTsoy/subscription-service/internal/billing"
+	"github.com/AntonTsoy/subscription-service/internal/logger"
 	"github.com/AntonTsoy/subscription-service/internal/models"
 	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
 	"github.com/go-chi/chi/v5"
 )
 
 type SubscriptionService interface {
 	Create(ctx context.Context, sub *models.Subscription) error
 	GetByID(ctx context.Context, id int) (*models.Subscription, error)
-	GetAll(ctx context.Context, limit, offset int) ([]models.Subscription, error)
+	GetAll(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, error)
+	GetAllWithCount(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, int, error)
+	GetPage(ctx context.Context, filter *models.SubscriptionFilter) ([]models.Subscription, *models.Cursor, int, error)
 	Update(ctx context.Context, sub *models.Subscription) error
-	Delete(ctx context.Context, id int) error
+	Patch(ctx context.Context, id, version int, apply func(*models.Subscription) (*models.Subscription, error)) (*models.Subscription, error)
+	Delete(ctx context.Context, id, version int) error
+	Batch(ctx context.Context, ops []models.BatchOperation, atomic bool) ([]models.BatchResult, error)
+	AddPrice(ctx context.Context, price *models.SubscriptionPrice) (bool, error)
+	ListPrices(ctx context.Context, subID int) ([]models.SubscriptionPrice, error)
+	AddDiscount(ctx context.Context, discount *models.SubscriptionDiscount) error
+	ListDiscounts(ctx context.Context, subID int) ([]models.SubscriptionDiscount, error)
+	Pause(ctx context.Context, id int) (*models.Subscription, error)
+	Resume(ctx context.Context, id int) (*models.Subscription, error)
+	Cancel(ctx context.Context, id int) (*models.Subscription, error)
+	AddPause(ctx context.Context, pause *models.SubscriptionPause) error
+	ListPauses(ctx context.Context, subID int) ([]models.SubscriptionPause, error)
+	DeletePause(ctx context.Context, subID, pauseID int) error
+	SaveExchangeRate(ctx context.Context, rate *models.ExchangeRate) error
+	DeleteExchangeRate(ctx context.Context, currency string, month time.Time) error
+	ListExchangeRates(ctx context.Context, filter *models.ExchangeRatesFilter) ([]models.ExchangeRate, error)
 	EvaluateTotalServiceSubscriptionsCost(ctx context.Context, subParams *models.ListSubscriptionsParams) (int, error)
+	EvaluateGroupedServiceSubscriptionsCost(ctx context.Context, subParams *models.ListSubscriptionsParams, groupBy models.CostGroupBy) ([]models.GroupCost, int, error)
+	EvaluateMonthlyServiceSubscriptionsCost(ctx context.Context, subParams *models.ListSubscriptionsParams) ([]models.MonthlyCost, error)
+	EvaluateForecastCost(ctx context.Context, subParams *models.ListSubscriptionsParams, months int) ([]models.MonthlyCost, int, error)
 }
 
 type SubsHandler struct {
@@ -37,33 +63,44 @@ func NewSubsHandler(service SubscriptionService) *SubsHandler {
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key header string false "Ключ идемпотентности: повтор с тем же ключом вернёт сохранённый ответ"
 // @Param        request body dto.SubscriptionRequest true "Данные новой подписки"
 // @Success      201 {object} dto.SubscriptionResponse "Созданная подписка"
-// @Failure      400 {string} string "Некорректные данные запроса"
-// @Failure      500 {string} string "Ошибка при создании подписки"
+// @Header       201 {string} ETag "Версия подписки"
+// @Failure      400 {object} dto.Problem "Некорректные данные запроса"
+// @Failure      409 {object} dto.Problem "Запрос с этим Idempotency-Key ещё выполняется"
+// @Failure      422 {object} dto.ValidationError "Нарушения правил валидации полей (или Idempotency-Key уже использован с другим запросом)"
+// @Failure      500 {object} dto.Problem "Ошибка при создании подписки"
 // @Router       /subscriptions [post]
 func (h *SubsHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
 	var req dto.SubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("RequestID=%s неправильное тело запроса для создания подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		if writeValidationError(w, r, err) {
+			return
+		}
+		logger.FromContext(r.Context()).Warn("неправильное тело запроса для создания подписки", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
 		return
 	}
 
 	sub, err := dto.ToSubscription(&req)
 	if err != nil {
-		log.Printf("RequestID=%s неправильное формат параметров тела запроса для создания подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "invalid request body parameter", http.StatusBadRequest)
+		if writeValidationError(w, r, err) {
+			return
+		}
+		logger.FromContext(r.Context()).Warn("неправильное формат параметров тела запроса для создания подписки", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request body parameter")
 		return
 	}
 
 	if err := h.service.Create(r.Context(), sub); err != nil {
-		log.Printf("RequestID=%s ошибка создания подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "failed to create subscription", http.StatusInternalServerError)
+		logError(r, "ошибка создания подписки", err)
+		problem.WriteError(w, r, err, "failed to create subscription")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(dto.ToSubscriptionResponse(sub))
 }
@@ -76,54 +113,129 @@ func (h *SubsHandler) CreateSubscription(w http.ResponseWriter, r *http.Request)
 // @Produce      json
 // @Param        id path int true "ID подписки"
 // @Success      200 {object} dto.SubscriptionResponse "Подписка"
-// @Failure      400 {string} string "Некорректный ID"
-// @Failure      404 {string} string "Подписка не найдена"
-// @Failure      500 {string} string "Ошибка при получении подписки"
+// @Header       200 {string} ETag "Версия подписки для If-Match"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      500 {object} dto.Problem "Ошибка при получении подписки"
 // @Router       /subscriptions/{id} [get]
 func (h *SubsHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
 	id, err := getIntPathParam(r, "id")
 	if err != nil {
-		log.Printf("RequestID=%s некорректная передача id параметра пути запроса: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "missing or invalid subscription id path parameter value", http.StatusBadRequest)
+		logger.FromContext(r.Context()).Warn("некорректная передача id параметра пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
 		return
 	}
 
 	sub, err := h.service.GetByID(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, models.ErrSubscriptionNotFound) {
-			log.Printf("RequestID=%s подписка нет в базе данных: %v", r.Context().Value("ReqID"), err)
-			http.Error(w, fmt.Sprintf("{'error': 'подписка id %d не найдена'}", id), http.StatusNotFound)
-			return
-		}
-		log.Printf("RequestID=%s ошибка получения подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "failed to get subscription", http.StatusInternalServerError)
+		logError(r, "ошибка получения подписки", err)
+		problem.WriteError(w, r, err, "failed to get subscription")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(dto.ToSubscriptionResponse(sub))
 }
 
 // GetAllSubscriptions godoc
 // @Summary      Список подписок
-// @Description  Возвращает список всех подписок с поддержкой пагинации
+// @Description  Возвращает список подписок с фильтрами, сортировкой и пагинацией
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
 // @Param        limit query int false "Максимальное количество элементов (по умолчанию 100)"
 // @Param        offset query int false "Смещение от начала (по умолчанию 0)"
-// @Success      200 {array} dto.SubscriptionResponse "Список подписок"
-// @Failure      500 {string} string "Ошибка при получении списка подписок"
+// @Param        user_id query string false "UUID пользователя"
+// @Param        service_name query string false "Название сервиса (точное совпадение)"
+// @Param        service_name_prefix query string false "Начало названия сервиса"
+// @Param        price_min query int false "Минимальная цена"
+// @Param        price_max query int false "Максимальная цена"
+// @Param        active_on query string false "Месяц, в котором подписка действует (MM-YYYY)"
+// @Param        open_ended query bool false "Только бессрочные подписки"
+// @Param        cursor query string false "Курсор страницы из next_cursor. С этим параметром (в том числе пустым) ответ — dto.SubscriptionListResponse, offset не используется"
+// @Param        envelope query bool false "Вернуть dto.SubscriptionListResponse с общим числом подписок и ссылкой на следующую страницу"
+// @Param        sort query string false "Поля сортировки через запятую, - для убывания: id, price, start_date, end_date, service_name, user_id" example(price,-start_date)
+// @Success      200 {object} dto.SubscriptionListResponse "Страница подписок (с envelope=true или cursor). Без этих параметров ответ — массив dto.SubscriptionResponse"
+// @Failure      400 {object} dto.Problem "Некорректные параметры запроса"
+// @Failure      500 {object} dto.Problem "Ошибка при получении списка подписок"
 // @Router       /subscriptions [get]
 func (h *SubsHandler) GetAllSubscriptions(w http.ResponseWriter, r *http.Request) {
-	limit := getIntQueryParam(r, "limit", 100)
-	offset := getIntQueryParam(r, "offset", 0)
+	query := r.URL.Query()
+	envelope := false
+	if value := query.Get("envelope"); value != "" {
+		var err error
+		if envelope, err = strconv.ParseBool(value); err != nil {
+			logger.FromContext(r.Context()).Warn("неправильный параметр envelope", "error", err)
+			problem.Write(w, r, http.StatusBadRequest, "invalid envelope parameter")
+			return
+		}
+	}
+
+	filter, err := dto.ToSubscriptionFilter(&dto.SubscriptionListRequest{
+		UserID:            query.Get("user_id"),
+		ServiceName:       query.Get("service_name"),
+		ServiceNamePrefix: query.Get("service_name_prefix"),
+		MinPrice:          query.Get("price_min"),
+		MaxPrice:          query.Get("price_max"),
+		ActiveOn:          query.Get("active_on"),
+		OpenEnded:         query.Get("open_ended"),
+		Sort:              query.Get("sort"),
+		Cursor:            query.Get("cursor"),
+	})
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр запроса списка подписок", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+	filter.Limit = getIntQueryParam(r, "limit", 100)
+	filter.Offset = getIntQueryParam(r, "offset", 0)
+
+	if query.Has("cursor") {
+		subscriptions, nextCursor, total, err := h.service.GetPage(r.Context(), filter)
+		if err != nil {
+			logError(r, "ошибка получения страницы подписок", err)
+			problem.WriteError(w, r, err, "failed to get subscriptions page")
+			return
+		}
+
+		var next *string
+		if nextCursor != nil {
+			link := cursorLink(r, dto.EncodeCursor(nextCursor))
+			next = &link
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(dto.ToSubscriptionListResponse(subscriptions, total, filter.Limit, 0, next, nextCursor))
+		return
+	}
+
+	if envelope {
+		subscriptions, total, err := h.service.GetAllWithCount(r.Context(), filter)
+		if err != nil {
+			logError(r, "ошибка получения подписок", err)
+			problem.WriteError(w, r, err, "failed to get all subscriptions")
+			return
+		}
+
+		var next *string
+		if len(subscriptions) > 0 && filter.Offset+len(subscriptions) < total {
+			link := pageLink(r, filter.Limit, filter.Offset+len(subscriptions))
+			next = &link
+		}
 
-	subscriptions, err := h.service.GetAll(r.Context(), limit, offset)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(dto.ToSubscriptionListResponse(subscriptions, total, filter.Limit, filter.Offset, next, nil))
+		return
+	}
+
+	subscriptions, err := h.service.GetAll(r.Context(), filter)
 	if err != nil {
-		log.Printf("RequestID=%s ошибка получения подписок: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "failed to get all subscriptions", http.StatusInternalServerError)
+		logError(r, "ошибка получения подписок", err)
+		problem.WriteError(w, r, err, "failed to get all subscriptions")
 		return
 	}
 
@@ -144,48 +256,122 @@ func (h *SubsHandler) GetAllSubscriptions(w http.ResponseWriter, r *http.Request
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "ID подписки"
+// @Param        If-Match header string false "ETag подписки из GET; без заголовка версия не проверяется"
 // @Param        request body dto.SubscriptionRequest true "Обновлённые данные подписки"
 // @Success      204 "Подписка успешно обновлена"
-// @Failure      400 {string} string "Некорректные данные запроса"
-// @Failure      404 {string} string "Подписка не найдена"
-// @Failure      500 {string} string "Ошибка при обновлении подписки"
+// @Header       204 {string} ETag "Новая версия подписки"
+// @Failure      400 {object} dto.Problem "Некорректные данные запроса"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      412 {object} dto.Problem "Подписка изменена: ETag не совпадает с If-Match"
+// @Failure      422 {object} dto.ValidationError "Нарушения правил валидации полей"
+// @Failure      500 {object} dto.Problem "Ошибка при обновлении подписки"
 // @Router       /subscriptions/{id} [put]
 func (h *SubsHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
 	var req dto.SubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("RequestID=%s неправильно тело запроса для обновления подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		if writeValidationError(w, r, err) {
+			return
+		}
+		logger.FromContext(r.Context()).Warn("неправильно тело запроса для обновления подписки", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
 		return
 	}
 
 	newSubData, err := dto.ToSubscription(&req)
 	if err != nil {
-		log.Printf("RequestID=%s неправильный параметр тела запроса: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "invalid request body parameter", http.StatusBadRequest)
+		if writeValidationError(w, r, err) {
+			return
+		}
+		logger.FromContext(r.Context()).Warn("неправильный параметр тела запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request body parameter")
 		return
 	}
 
 	newSubData.ID, err = getIntPathParam(r, "id")
 	if err != nil {
-		log.Printf("RequestID=%s неправильный параметр пути запроса: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "missing or invalid subscription id path parameter value", http.StatusBadRequest)
+		logger.FromContext(r.Context()).Warn("неправильный параметр пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	newSubData.Version, err = ifMatchVersion(r)
+	if err != nil {
+		logError(r, "неправильный заголовок If-Match", err)
+		problem.WriteError(w, r, err, "invalid If-Match header")
 		return
 	}
 
 	if err := h.service.Update(r.Context(), newSubData); err != nil {
-		if errors.Is(err, models.ErrSubscriptionNotFound) {
-			log.Printf("RequestID=%s подписка не существует: %v", r.Context().Value("ReqID"), err)
-			http.Error(w, fmt.Sprintf("{'error': 'подписка id %d не найдена'}", newSubData.ID), http.StatusNotFound)
-			return
-		}
-		log.Printf("RequestID=%s ошибка обновления подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "failed to update subscription", http.StatusInternalServerError)
+		logError(r, "ошибка обновления подписки", err)
+		problem.WriteError(w, r, err, "failed to update subscription")
 		return
 	}
 
+	w.Header().Set("ETag", etag(newSubData.Version))
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// PatchSubscription godoc
+// @Summary      Частично обновить подписку
+// @Description  Применяет JSON Merge Patch (RFC 7386): меняются только переданные поля, null удаляет необязательное поле (например, "end_date": null делает подписку бессрочной)
+// @Tags         subscriptions
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        id path int true "ID подписки"
+// @Param        If-Match header string false "ETag подписки из GET; без заголовка версия не проверяется"
+// @Param        request body dto.SubscriptionRequest true "Изменяемые поля подписки"
+// @Success      200 {object} dto.SubscriptionResponse "Обновлённая подписка"
+// @Header       200 {string} ETag "Новая версия подписки"
+// @Failure      400 {object} dto.Problem "Некорректный патч"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      412 {object} dto.Problem "Подписка изменена: ETag не совпадает с If-Match"
+// @Failure      415 {object} dto.Problem "Тип содержимого не application/merge-patch+json"
+// @Failure      422 {object} dto.ValidationError "Нарушения правил валидации полей подписки после патча"
+// @Failure      500 {object} dto.Problem "Ошибка при обновлении подписки"
+// @Router       /subscriptions/{id} [patch]
+func (h *SubsHandler) PatchSubscription(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/merge-patch+json" {
+		logger.FromContext(r.Context()).Warn("неподдерживаемый тип патча", "content_type", r.Header.Get("Content-Type"))
+		problem.Write(w, r, http.StatusUnsupportedMediaType, "content type must be application/merge-patch+json")
+		return
+	}
+
+	id, err := getIntPathParam(r, "id")
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр пути запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		logError(r, "неправильный заголовок If-Match", err)
+		problem.WriteError(w, r, err, "invalid If-Match header")
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("ошибка чтения тела патча", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	sub, err := h.service.Patch(r.Context(), id, version, func(sub *models.Subscription) (*models.Subscription, error) {
+		return dto.ApplyMergePatch(sub, patch)
+	})
+	if err != nil {
+		logError(r, "ошибка частичного обновления подписки", err)
+		problem.WriteError(w, r, err, "failed to patch subscription")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(sub.Version))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ToSubscriptionResponse(sub))
+}
+
 // DeleteSubscription godoc
 // @Summary      Удалить подписку
 // @Description  Удаляет подписку по её ID
@@ -193,27 +379,31 @@ func (h *SubsHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request)
 // @Accept       json
 // @Produce      json
 // @Param        id path int true "ID подписки"
+// @Param        If-Match header string false "ETag подписки из GET; без заголовка версия не проверяется"
 // @Success      204 "Подписка успешно удалена"
-// @Failure      400 {string} string "Некорректный ID"
-// @Failure      404 {string} string "Подписка не найдена"
-// @Failure      500 {string} string "Ошибка при удалении подписки"
+// @Failure      400 {object} dto.Problem "Некорректный ID"
+// @Failure      404 {object} dto.Problem "Подписка не найдена"
+// @Failure      412 {object} dto.Problem "Подписка изменена: ETag не совпадает с If-Match"
+// @Failure      500 {object} dto.Problem "Ошибка при удалении подписки"
 // @Router       /subscriptions/{id} [delete]
 func (h *SubsHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
 	id, err := getIntPathParam(r, "id")
 	if err != nil {
-		log.Printf("RequestID=%s неправильное тело запроса для удаления подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "missing or invalid subscription id path parameter value", http.StatusBadRequest)
+		logger.FromContext(r.Context()).Warn("неправильное тело запроса для удаления подписки", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), id); err != nil {
-		if errors.Is(err, models.ErrSubscriptionNotFound) {
-			log.Printf("RequestID=%s подписка не существует: %v", r.Context().Value("ReqID"), err)
-			http.Error(w, fmt.Sprintf("{'error': 'подписка id %d не найдена'}", id), http.StatusNotFound)
-			return
-		}
-		log.Printf("RequestID=%s ошибка удаления подписки: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "failed to delete subscription", http.StatusInternalServerError)
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		logError(r, "неправильный заголовок If-Match", err)
+		problem.WriteError(w, r, err, "invalid If-Match header")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, version); err != nil {
+		logError(r, "ошибка удаления подписки", err)
+		problem.WriteError(w, r, err, "failed to delete subscription")
 		return
 	}
 
@@ -223,6 +413,7 @@ func (h *SubsHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request)
 // TotalServiceSubscriptionsCost godoc
 // @Summary      Общая стоимость подписок
 // @Description  Считает суммарную стоимость подписок пользователя на конкретный сервис за период [start; end]
+// @Description  При указании group_by возвращает итоги по каждой группе и общую сумму
 // @Tags         subscriptions
 // @Accept       json
 // @Produce      json
@@ -230,40 +421,168 @@ func (h *SubsHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request)
 // @Param        end path string true "Конец периода (MM-YYYY)"
 // @Param        user_id query string false "UUID пользователя (опционально)"
 // @Param        service_name query string false "Название сервиса (опционально)"
-// @Success      200 {object} map[string]int "Суммарная стоимость подписок"
-// @Failure      400 {string} string "Некорректные параметры запроса"
-// @Failure      500 {string} string "Ошибка при вычислении стоимости"
+// @Param        billing_period query string false "Период оплаты подписок (опционально)" Enums(weekly, monthly, quarterly, yearly)
+// @Param        normalize query bool false "Считать цены в месячном эквиваленте вместо фактических списаний (по умолчанию false)"
+// @Param        proration query string false "Начисление за неполные месяцы подписок с датами до дня (по умолчанию full)" Enums(full, daily, renewal)
+// @Param        currency query string false "Валюта результата, код ISO 4217 (по умолчанию RUB)"
+// @Param        group_by query string false "Группировка итогов: service_name или user_id (опционально)" Enums(service_name, user_id)
+// @Success      200 {object} dto.TotalCostResponse "Суммарная стоимость подписок (без group_by) или dto.GroupedCostResponse (с group_by)"
+// @Failure      400 {object} dto.Problem "Некорректные параметры запроса"
+// @Failure      422 {object} dto.Problem "Нет курсов валют для пересчёта"
+// @Failure      500 {object} dto.Problem "Ошибка при вычислении стоимости"
 // @Router       /subscriptions/{start}/{end}/total-cost [get]
 func (h *SubsHandler) TotalServiceSubscriptionsCost(w http.ResponseWriter, r *http.Request) {
-	var req dto.TotalSubscriptionsCostRequest
-	req.StartDate = chi.URLParam(r, "start")
-	req.EndDate = chi.URLParam(r, "end")
-	if req.StartDate == "" || req.EndDate == "" {
-		log.Printf("RequestID=%s некорректный интервал для подсчета стоимости подписок", r.Context().Value("ReqID"))
-		http.Error(w, "invalid subscription perion in path parameter", http.StatusBadRequest)
+	subParams, ok := parseCostPeriodRequest(w, r)
+	if !ok {
 		return
 	}
 
-	req.UserID = r.URL.Query().Get("user_id")
-	req.ServiceName = r.URL.Query().Get("service_name")
+	if groupByParam := r.URL.Query().Get("group_by"); groupByParam != "" {
+		groupBy, err := dto.ToCostGroupBy(groupByParam)
+		if err != nil {
+			logger.FromContext(r.Context()).Warn("неправильный параметр группировки", "error", err)
+			problem.Write(w, r, http.StatusBadRequest, "invalid group_by query parameter")
+			return
+		}
 
-	subParams, err := dto.ToListSubscriptionsParams(&req)
-	if err != nil {
-		log.Printf("RequestID=%s неправильный параметр тела запроса: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "invalid request body parameter", http.StatusBadRequest)
+		groups, totalCost, err := h.service.EvaluateGroupedServiceSubscriptionsCost(r.Context(), subParams, groupBy)
+		if err != nil {
+			logError(r, "ошибка получения стоимости подписок по группам", err)
+			problem.WriteError(w, r, err, "failed to get grouped subscriptions cost for period")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(dto.ToGroupedCostResponse(groupBy, groups, totalCost, subParams.Options.Currency))
 		return
 	}
 
 	totalCost, err := h.service.EvaluateTotalServiceSubscriptionsCost(r.Context(), subParams)
 	if err != nil {
-		log.Printf("RequestID=%s ошибка получения стоимости подписок: %v", r.Context().Value("ReqID"), err)
-		http.Error(w, "failed to get subscriptions cost for period", http.StatusInternalServerError)
+		logError(r, "ошибка получения стоимости подписок", err)
+		problem.WriteError(w, r, err, "failed to get subscriptions cost for period")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.TotalCostResponse{TotalCost: totalCost, Currency: subParams.Options.Currency})
+}
+
+// MonthlyServiceSubscriptionsCost godoc
+// @Summary      Помесячная стоимость подписок
+// @Description  Возвращает стоимость подписок по каждому месяцу периода [start; end] с теми же фильтрами, что и общая стоимость
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        start path string true "Начало периода (MM-YYYY)"
+// @Param        end path string true "Конец периода (MM-YYYY)"
+// @Param        user_id query string false "UUID пользователя (опционально)"
+// @Param        service_name query string false "Название сервиса (опционально)"
+// @Param        billing_period query string false "Период оплаты подписок (опционально)" Enums(weekly, monthly, quarterly, yearly)
+// @Param        normalize query bool false "Считать цены в месячном эквиваленте вместо фактических списаний (по умолчанию false)"
+// @Param        proration query string false "Начисление за неполные месяцы подписок с датами до дня (по умолчанию full)" Enums(full, daily, renewal)
+// @Param        currency query string false "Валюта результата, код ISO 4217 (по умолчанию RUB)"
+// @Success      200 {array} dto.MonthlyCostResponse "Стоимость подписок по месяцам"
+// @Failure      400 {object} dto.Problem "Некорректные параметры запроса или период длиннее 120 месяцев"
+// @Failure      422 {object} dto.Problem "Нет курсов валют для пересчёта"
+// @Failure      500 {object} dto.Problem "Ошибка при вычислении стоимости"
+// @Router       /subscriptions/{start}/{end}/monthly-cost [get]
+func (h *SubsHandler) MonthlyServiceSubscriptionsCost(w http.ResponseWriter, r *http.Request) {
+	subParams, ok := parseCostPeriodRequest(w, r)
+	if !ok {
+		return
+	}
+	if billing.MonthsBetween(subParams.StartDate, subParams.EndDate) > dto.MaxMonthlyCostMonths {
+		logger.FromContext(r.Context()).Warn("слишком длинный период помесячной стоимости подписок")
+		problem.Write(w, r, http.StatusBadRequest, "period must not be longer than 120 months")
+		return
+	}
+
+	costs, err := h.service.EvaluateMonthlyServiceSubscriptionsCost(r.Context(), subParams)
+	if err != nil {
+		logError(r, "ошибка получения помесячной стоимости подписок", err)
+		problem.WriteError(w, r, err, "failed to get monthly subscriptions cost for period")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]int{"totalCost": totalCost})
+	json.NewEncoder(w).Encode(dto.ToMonthlyCostResponse(costs))
+}
+
+// ForecastSubscriptionsCost godoc
+// @Summary      Прогноз расходов на подписки
+// @Description  Прогнозирует стоимость подписок по месяцам на months месяцев вперёд, начиная со следующего месяца
+// @Description  Бессрочные подписки считаются продолжающимися, завершённые — заканчиваются в end_date
+// @Description  Для будущих месяцев без курса валюты используется последний известный курс
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Param        months query int false "Количество месяцев прогноза, от 1 до 120 (по умолчанию 12)"
+// @Param        user_id query string false "UUID пользователя (опционально)"
+// @Param        service_name query string false "Название сервиса (опционально)"
+// @Param        billing_period query string false "Период оплаты подписок (опционально)" Enums(weekly, monthly, quarterly, yearly)
+// @Param        normalize query bool false "Считать цены в месячном эквиваленте вместо фактических списаний (по умолчанию false)"
+// @Param        proration query string false "Начисление за неполные месяцы подписок с датами до дня (по умолчанию full)" Enums(full, daily, renewal)
+// @Param        currency query string false "Валюта результата, код ISO 4217 (по умолчанию RUB)"
+// @Success      200 {object} dto.ForecastResponse "Прогноз стоимости по месяцам и итог"
+// @Failure      400 {object} dto.Problem "Некорректные параметры запроса"
+// @Failure      422 {object} dto.Problem "Нет курсов валют для пересчёта"
+// @Failure      500 {object} dto.Problem "Ошибка при вычислении прогноза"
+// @Router       /subscriptions/forecast [get]
+func (h *SubsHandler) ForecastSubscriptionsCost(w http.ResponseWriter, r *http.Request) {
+	req := costFiltersRequest(r)
+	subParams, months, err := dto.ToForecastParams(&req, r.URL.Query().Get("months"))
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр запроса прогноза", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid query parameter")
+		return
+	}
+
+	costs, totalCost, err := h.service.EvaluateForecastCost(r.Context(), subParams, months)
+	if err != nil {
+		logError(r, "ошибка прогноза стоимости подписок", err)
+		problem.WriteError(w, r, err, "failed to forecast subscriptions cost")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dto.ToForecastResponse(costs, totalCost, subParams.Options.Currency))
+}
+
+func parseCostPeriodRequest(w http.ResponseWriter, r *http.Request) (*models.ListSubscriptionsParams, bool) {
+	req := costFiltersRequest(r)
+	req.StartDate = chi.URLParam(r, "start")
+	req.EndDate = chi.URLParam(r, "end")
+	if req.StartDate == "" || req.EndDate == "" {
+		logger.FromContext(r.Context()).Warn("некорректный интервал для подсчета стоимости подписок")
+		problem.Write(w, r, http.StatusBadRequest, "invalid subscription period in path parameter")
+		return nil, false
+	}
+
+	subParams, err := dto.ToListSubscriptionsParams(&req)
+	if err != nil {
+		logger.FromContext(r.Context()).Warn("неправильный параметр тела запроса", "error", err)
+		problem.Write(w, r, http.StatusBadRequest, "invalid request body parameter")
+		return nil, false
+	}
+	return subParams, true
+}
+
+// costFiltersRequest читает из query фильтры подписок и параметры расчёта стоимости.
+func costFiltersRequest(r *http.Request) dto.TotalSubscriptionsCostRequest {
+	query := r.URL.Query()
+	return dto.TotalSubscriptionsCostRequest{
+		UserID:        query.Get("user_id"),
+		ServiceName:   query.Get("service_name"),
+		BillingPeriod: query.Get("billing_period"),
+		Normalize:     query.Get("normalize"),
+		Proration:     query.Get("proration"),
+		Currency:      query.Get("currency"),
+	}
 }
 
 func getIntPathParam(r *http.Request, key string) (int, error) {
@@ -282,6 +601,69 @@ func getIntPathParam(r *http.Request, key string) (int, error) {
 	return value, nil
 }
 
+// logError пишет в лог запроса ошибку сервиса: ошибки клиента — с уровнем
+// Warn, внутренние — с уровнем Error.
+func logError(r *http.Request, msg string, err error, args ...any) {
+	args = append(args, "error", err)
+	logger.FromContext(r.Context()).Log(r.Context(), problem.LogLevel(err), msg, args...)
+}
+
+// writeValidationError отвечает 422 со списком нарушений, если err — ошибка
+// валидации полей или неверный тип поля в JSON.
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var verr *dto.ValidationError
+	if !errors.As(err, &verr) {
+		if verr = dto.DecodeValidationError(err); verr == nil {
+			return false
+		}
+	}
+
+	logger.FromContext(r.Context()).Warn("данные запроса не прошли валидацию", "error", verr)
+	problem.WriteValidation(w, r, verr)
+	return true
+}
+
+// etag возвращает сильный ETag для версии подписки.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion возвращает версию подписки из заголовка If-Match или 0, если
+// заголовка нет или он равен "*". Поддерживается один сильный ETag: слабый
+// или список ETag считаются несовпадающими.
+func ifMatchVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	unquoted, ok := strings.CutPrefix(value, `"`)
+	if ok {
+		unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	}
+	version, err := strconv.Atoi(unquoted)
+	if !ok || err != nil || version <= 0 {
+		return 0, fmt.Errorf("%w: неподдерживаемый ETag в If-Match: %s", models.ErrVersionMismatch, value)
+	}
+	return version, nil
+}
+
+// pageLink возвращает ссылку на тот же запрос с другими limit и offset.
+func pageLink(r *http.Request, limit, offset int) string {
+	query := r.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// cursorLink возвращает ссылку на тот же запрос со следующим курсором.
+func cursorLink(r *http.Request, cursor string) string {
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	query.Del("offset")
+	return r.URL.Path + "?" + query.Encode()
+}
+
 func getIntQueryParam(r *http.Request, key string, defaultValue int) int {
 	valueStr := r.URL.Query().Get(key)
 	if valueStr == "" {
@@ -0,0 +1,238 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+const (
+	maxKeyLength = 255
+
+	// lease — время, на которое запрос занимает ключ. Оно больше таймаута
+	// запроса, поэтому ключ выполняющегося запроса не перехватывается, а ключ
+	// упавшего процесса освобождается для повтора.
+	lease = time.Minute
+)
+
+// perResponseHeaders — заголовки, которые относятся к конкретному ответу, а не к
+// результату запроса: при повторе они не берутся из сохранённого ответа.
+var perResponseHeaders = map[string]bool{
+	logger.RequestIDHeader: true,
+	"Date":                 true,
+}
+
+// Store хранит ключи идемпотентности и ответы на запросы с ними.
+type Store interface {
+	ReserveIdempotencyKey(ctx context.Context, key *models.IdempotencyKey, now time.Time) (*models.IdempotencyKey, error)
+	SaveIdempotentResponse(ctx context.Context, key *models.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, client, key string) error
+}
+
+// Cleaner удаляет истёкшие ключи идемпотентности.
+type Cleaner interface {
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rrw *recordingResponseWriter) WriteHeader(code int) {
+	rrw.statusCode = code
+	rrw.ResponseWriter.WriteHeader(code)
+}
+
+func (rrw *recordingResponseWriter) Write(b []byte) (int, error) {
+	rrw.body.Write(b)
+	return rrw.ResponseWriter.Write(b)
+}
+
+// Middleware делает запросы с заголовком Idempotency-Key идемпотентными: ответ на
+// первый запрос сохраняется на ttl и возвращается при повторах с тем же ключом.
+// Повтор с другим телом получает 422, повтор во время выполнения первого запроса — 409.
+// Ответы 5xx и паники не сохраняются, чтобы запрос можно было повторить.
+// Ключи разных клиентов не пересекаются, см. clientID.
+func Middleware(store Store, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				logger.FromContext(r.Context()).Warn("слишком длинный Idempotency-Key", "length", len(key))
+				problem.Write(w, r, http.StatusBadRequest, "Idempotency-Key is too long")
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				logger.FromContext(r.Context()).Warn("ошибка чтения тела запроса", "error", err)
+				problem.Write(w, r, http.StatusBadRequest, "invalid request")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now().UTC()
+			reserved := &models.IdempotencyKey{
+				Client:      clientID(r),
+				Key:         key,
+				RequestHash: requestHash(r, body),
+				LockedUntil: now.Add(lease),
+				ExpiresAt:   now.Add(ttl),
+			}
+			saved, err := store.ReserveIdempotencyKey(r.Context(), reserved, now)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("ошибка проверки ключа идемпотентности", "error", err)
+				problem.Write(w, r, http.StatusInternalServerError, "failed to check idempotency key")
+				return
+			}
+			if saved != nil {
+				replay(w, r, saved, reserved.RequestHash)
+				return
+			}
+
+			// Контекст запроса к моменту записи результата может быть отменён
+			// по таймауту, а результат нужно записать в любом случае.
+			ctx := context.WithoutCancel(r.Context())
+			release := func() {
+				if err := store.DeleteIdempotencyKey(ctx, reserved.Client, key); err != nil {
+					logger.FromContext(r.Context()).Error("ошибка освобождения ключа идемпотентности", "error", err)
+				}
+			}
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			rrw := &recordingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(rrw, r)
+
+			if rrw.statusCode >= http.StatusInternalServerError {
+				release()
+				return
+			}
+
+			reserved.StatusCode = rrw.statusCode
+			header := rrw.Header().Clone()
+			for name := range perResponseHeaders {
+				header.Del(name)
+			}
+			reserved.ResponseHeader, _ = json.Marshal(header)
+			reserved.ResponseBody = rrw.body.Bytes()
+			if err := store.SaveIdempotentResponse(ctx, reserved); err != nil {
+				logger.FromContext(r.Context()).Error("ошибка сохранения ответа для ключа идемпотентности", "error", err)
+			}
+		})
+	}
+}
+
+// replay отвечает на повтор запроса с уже известным ключом.
+func replay(w http.ResponseWriter, r *http.Request, saved *models.IdempotencyKey, hash string) {
+	switch {
+	case saved.RequestHash != hash:
+		logger.FromContext(r.Context()).Warn("Idempotency-Key использован с другим запросом", "key", saved.Key)
+		problem.Write(w, r, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+	case saved.StatusCode == 0:
+		logger.FromContext(r.Context()).Warn("запрос с Idempotency-Key ещё выполняется", "key", saved.Key)
+		problem.Write(w, r, http.StatusConflict, "request with this Idempotency-Key is still in progress")
+	default:
+		var header http.Header
+		if err := json.Unmarshal(saved.ResponseHeader, &header); err != nil {
+			logger.FromContext(r.Context()).Error("ошибка чтения сохранённых заголовков ответа", "error", err)
+		}
+		for name, values := range header {
+			if !perResponseHeaders[http.CanonicalHeaderKey(name)] {
+				w.Header()[name] = values
+			}
+		}
+		body := saved.ResponseBody
+		if header.Get("Content-Type") == problem.ContentType {
+			body = withRequestID(r, body)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(saved.StatusCode)
+		w.Write(body)
+	}
+}
+
+// withRequestID заменяет в сохранённом описании ошибки request_id первого
+// запроса на ID повторного, чтобы он совпадал с X-Request-ID ответа.
+func withRequestID(r *http.Request, body []byte) []byte {
+	var p dto.Problem
+	if err := json.Unmarshal(body, &p); err != nil {
+		logger.FromContext(r.Context()).Error("ошибка чтения сохранённого описания ошибки", "error", err)
+		return body
+	}
+	p.RequestID = logger.RequestID(r.Context())
+
+	replayed, err := json.Marshal(&p)
+	if err != nil {
+		logger.FromContext(r.Context()).Error("ошибка записи описания ошибки", "error", err)
+		return body
+	}
+	return append(replayed, '\n')
+}
+
+// RunCleanup раз в interval удаляет истёкшие ключи идемпотентности, пока не
+// отменён ctx. Запросы истёкшие ключи не удаляют, а только перезаписывают.
+func RunCleanup(ctx context.Context, cleaner Cleaner, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := cleaner.DeleteExpiredIdempotencyKeys(ctx, time.Now().UTC())
+			if err != nil {
+				logger.FromContext(ctx).Error("ошибка очистки ключей идемпотентности", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				logger.FromContext(ctx).Info("удалены истёкшие ключи идемпотентности", "count", deleted)
+			}
+		}
+	}
+}
+
+// clientID определяет, кому принадлежит ключ: клиенту с заголовком
+// Authorization — по хешу его значения, иначе — по IP-адресу.
+func clientID(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		sum := sha256.Sum256([]byte(auth))
+		return "auth:" + hex.EncodeToString(sum[:])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// requestHash — хеш метода, пути и тела запроса: ключ нельзя переиспользовать
+// ни для другого тела, ни для другой ручки.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,239 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+	"github.com/AntonTsoy/subscription-service/internal/transport/problem"
+)
+
+type memoryStore map[string]models.IdempotencyKey
+
+func (s memoryStore) ReserveIdempotencyKey(_ context.Context, key *models.IdempotencyKey, now time.Time) (*models.IdempotencyKey, error) {
+	saved, ok := s[key.Client+" "+key.Key]
+	leaseExpired := saved.StatusCode == 0 && !saved.LockedUntil.After(now) && saved.RequestHash == key.RequestHash
+	if ok && saved.ExpiresAt.After(now) && !leaseExpired {
+		return &saved, nil
+	}
+	s[key.Client+" "+key.Key] = *key
+	return nil, nil
+}
+
+func (s memoryStore) SaveIdempotentResponse(_ context.Context, key *models.IdempotencyKey) error {
+	s[key.Client+" "+key.Key] = *key
+	return nil
+}
+
+func (s memoryStore) DeleteIdempotencyKey(_ context.Context, client, key string) error {
+	delete(s, client+" "+key)
+	return nil
+}
+
+func (s memoryStore) DeleteExpiredIdempotencyKeys(_ context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for k, saved := range s {
+		if !saved.ExpiresAt.After(now) {
+			delete(s, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+type testServer struct {
+	store   memoryStore
+	calls   int
+	status  int
+	handler http.Handler
+}
+
+func newTestServer() *testServer {
+	ts := &testServer{store: memoryStore{}, status: http.StatusCreated}
+	ts.handler = Middleware(ts.store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.calls++
+		if ts.status == 0 {
+			panic("handler failed")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(ts.status)
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	return ts
+}
+
+func (ts *testServer) send(key, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	ts.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	ts := newTestServer()
+
+	first := ts.send("key-1", `{"price": 100}`)
+	replayed := ts.send("key-1", `{"price": 100}`)
+	if ts.calls != 1 {
+		t.Fatalf("handler called %d times, want 1", ts.calls)
+	}
+	if replayed.Code != first.Code || replayed.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", replayed.Code, replayed.Body, first.Code, first.Body)
+	}
+	if replayed.Header().Get("Content-Type") != "application/json" || replayed.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("replay headers = %v", replayed.Header())
+	}
+
+	if rec := ts.send("key-1", `{"price": 200}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reuse with other body: status = %d, want 422", rec.Code)
+	}
+
+	ts.status = http.StatusInternalServerError
+	ts.send("key-3", `{}`)
+	if len(ts.store) != 1 {
+		t.Errorf("key with 5xx response was not released: %v", ts.store)
+	}
+
+	ts.calls = 0
+	ts.send("", `{}`)
+	ts.send("", `{}`)
+	if ts.calls != 2 {
+		t.Errorf("requests without key: handler called %d times, want 2", ts.calls)
+	}
+}
+
+func TestMiddlewareReplayRequestID(t *testing.T) {
+	handler := logger.Middleware(slog.New(slog.NewTextHandler(io.Discard, nil)))(
+		Middleware(memoryStore{}, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			problem.Write(w, r, http.StatusBadRequest, "invalid request")
+		})),
+	)
+
+	for _, requestID := range []string{"first-request", "retried-request"} {
+		req := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(`{"price": 100}`))
+		req.Header.Set("Idempotency-Key", "key-1")
+		req.Header.Set(logger.RequestIDHeader, requestID)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Values(logger.RequestIDHeader); len(got) != 1 || got[0] != requestID {
+			t.Errorf("%s = %v, want [%s]", logger.RequestIDHeader, got, requestID)
+		}
+		var p dto.Problem
+		if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+			t.Fatalf("тело ответа не JSON: %v", err)
+		}
+		if p.RequestID != requestID || p.Detail != "invalid request" {
+			t.Errorf("problem = %+v, want request_id %q", p, requestID)
+		}
+	}
+}
+
+func TestMiddlewareLease(t *testing.T) {
+	ts := newTestServer()
+	ts.send("key-1", `{"price": 100}`)
+
+	var saved models.IdempotencyKey
+	for k, v := range ts.store {
+		saved = v
+		delete(ts.store, k)
+	}
+
+	// Ключ занят выполняющимся запросом, пока не истекла его блокировка.
+	saved.StatusCode = 0
+	saved.LockedUntil = time.Now().Add(time.Minute)
+	ts.store[saved.Client+" "+saved.Key] = saved
+	if rec := ts.send("key-1", `{"price": 100}`); rec.Code != http.StatusConflict {
+		t.Errorf("request in progress: status = %d, want 409", rec.Code)
+	}
+
+	saved.LockedUntil = time.Now().Add(-time.Second)
+	ts.store[saved.Client+" "+saved.Key] = saved
+	if rec := ts.send("key-1", `{"price": 200}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expired lease with other body: status = %d, want 422", rec.Code)
+	}
+
+	ts.calls = 0
+	if rec := ts.send("key-1", `{"price": 100}`); rec.Code != http.StatusCreated || ts.calls != 1 {
+		t.Errorf("expired lease: status = %d, calls = %d, want 201 and 1", rec.Code, ts.calls)
+	}
+}
+
+func TestMiddlewarePanicReleasesKey(t *testing.T) {
+	ts := newTestServer()
+	ts.status = 0
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was not propagated")
+			}
+		}()
+		ts.send("key-1", `{}`)
+	}()
+	if len(ts.store) != 0 {
+		t.Errorf("key was not released after panic: %v", ts.store)
+	}
+
+	ts.status = http.StatusCreated
+	if rec := ts.send("key-1", `{}`); rec.Code != http.StatusCreated {
+		t.Errorf("retry after panic: status = %d, want 201", rec.Code)
+	}
+}
+
+func TestMiddlewareScopesKeysByClient(t *testing.T) {
+	ts := newTestServer()
+
+	ts.send("key-1", `{"price": 100}`, "Authorization", "Bearer alice")
+	if rec := ts.send("key-1", `{"price": 200}`, "Authorization", "Bearer bob"); rec.Code != http.StatusCreated {
+		t.Errorf("same key of another client: status = %d, want 201", rec.Code)
+	}
+	if rec := ts.send("key-1", `{"price": 300}`); rec.Code != http.StatusCreated {
+		t.Errorf("same key without Authorization: status = %d, want 201", rec.Code)
+	}
+	if ts.calls != 3 {
+		t.Errorf("handler called %d times, want 3", ts.calls)
+	}
+	if rec := ts.send("key-1", `{"price": 100}`, "Authorization", "Bearer alice"); rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("repeat of first client was not replayed: %d", rec.Code)
+	}
+}
+
+func TestRunCleanup(t *testing.T) {
+	store := memoryStore{
+		"ip:1 old": {Key: "old", ExpiresAt: time.Now().Add(-time.Minute)},
+		"ip:1 new": {Key: "new", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		RunCleanup(ctx, store, time.Millisecond)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	if _, ok := store["ip:1 old"]; ok {
+		t.Error("expired key was not deleted")
+	}
+	if _, ok := store["ip:1 new"]; !ok {
+		t.Error("live key was deleted")
+	}
+}
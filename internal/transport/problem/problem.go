@@ -0,0 +1,168 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/AntonTsoy/subscription-service/internal/i18n"
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+)
+
+// ContentType — тип содержимого ответов с ошибками.
+const ContentType = "application/problem+json"
+
+// kind — HTTP-представление доменной ошибки. detail объясняет клиенту, что
+// именно не так с запросом.
+type kind struct {
+	status int
+	title  string
+	detail string
+}
+
+// catalog сопоставляет коды доменных ошибок из models статусам HTTP. Ошибки,
+// код которых здесь не указан, считаются внутренними (500).
+var catalog = map[models.ErrorCode]kind{
+	models.CodeSubscriptionNotFound: {http.StatusNotFound, "Subscription not found",
+		"subscription with this id does not exist"},
+	models.CodePauseNotFound: {http.StatusNotFound, "Subscription pause not found",
+		"subscription has no pause with this id"},
+	models.CodeExchangeRateNotFound: {http.StatusNotFound, "Exchange rate not found",
+		"no exchange rate is set for this currency and month"},
+	models.CodeInvalidPeriod: {http.StatusUnprocessableEntity, "Period ends before it starts",
+		"end of the period must not be before its start"},
+	models.CodeInvalidValue: {http.StatusUnprocessableEntity, "Invalid field value",
+		"field value is not allowed"},
+	models.CodePriceOutsidePeriod: {http.StatusUnprocessableEntity, "Price change is outside of subscription period",
+		"effective_from is outside of subscription period"},
+	models.CodeDiscountOutsidePeriod: {http.StatusUnprocessableEntity, "Discount is outside of subscription period",
+		"discount months are outside of subscription period"},
+	models.CodePauseOutsidePeriod: {http.StatusUnprocessableEntity, "Pause is outside of subscription period",
+		"start_month is outside of subscription period"},
+	models.CodeExchangeRateMissing: {http.StatusUnprocessableEntity, "Missing exchange rates",
+		"exchange rates listed in missing_rates are required for conversion"},
+	models.CodeInvalidStatusTransition: {http.StatusConflict, "Invalid subscription status transition",
+		"subscription cannot move to this status from its current status"},
+	models.CodePauseOverlap: {http.StatusConflict, "Pause overlaps with another subscription pause",
+		"pause overlaps with another subscription pause"},
+	models.CodePauseInProgress: {http.StatusConflict, "Subscription pause is in progress",
+		"pause is in progress, resume subscription instead"},
+	models.CodeDuplicateSubscription: {http.StatusConflict, "Subscription already exists",
+		"user already has a subscription to this service with the same start_date"},
+	models.CodeConflict: {http.StatusConflict, "Conflicting concurrent change",
+		"data was changed by a concurrent request, retry the request"},
+	models.CodeVersionMismatch: {http.StatusPreconditionFailed, "Subscription version does not match",
+		"subscription version does not match If-Match"},
+	models.CodeInvalidPatch: {http.StatusBadRequest, "Invalid merge patch",
+		"merge patch cannot be applied to the subscription"},
+}
+
+// Status возвращает статус HTTP и заголовок на языке lang для ошибки err.
+func Status(err error, lang i18n.Lang) (int, string) {
+	if _, k, ok := lookup(err); ok {
+		return k.status, i18n.Text(lang, k.title)
+	}
+	return http.StatusInternalServerError, i18n.Text(lang, http.StatusText(http.StatusInternalServerError))
+}
+
+// LogLevel возвращает уровень, с которым стоит логировать err: ошибки клиента
+// (доменные из каталога и ошибки валидации) — Warn, остальные — Error.
+func LogLevel(err error) slog.Level {
+	var verr *dto.ValidationError
+	if _, _, ok := lookup(err); ok || errors.As(err, &verr) {
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// lookup ищет в цепочке err доменную ошибку с кодом из каталога.
+func lookup(err error) (models.ErrorCode, kind, bool) {
+	for err != nil {
+		var domainErr *models.Error
+		if !errors.As(err, &domainErr) {
+			break
+		}
+		if k, ok := catalog[domainErr.Code]; ok {
+			return domainErr.Code, k, true
+		}
+		err = domainErr.Err
+	}
+	return "", kind{}, false
+}
+
+// typeURI — URI вида ошибки: /problems/<код в нижнем регистре через дефис>.
+func typeURI(code models.ErrorCode) string {
+	return "/problems/" + strings.ToLower(strings.ReplaceAll(string(code), "_", "-"))
+}
+
+// Write отвечает ошибкой без доменного типа: type — about:blank, title — текст статуса.
+// Заголовок и detail переводятся на язык из Accept-Language.
+func Write(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	lang := i18n.FromRequest(r)
+	write(w, r, lang, &dto.Problem{
+		Type:   "about:blank",
+		Title:  i18n.Text(lang, http.StatusText(status)),
+		Status: status,
+		Detail: i18n.Text(lang, detail),
+	})
+}
+
+// WriteError отвечает на ошибку сервиса: доменные ошибки получают статус и
+// описание из каталога, остальные — 500 с описанием detail. Ошибка валидации отдаётся
+// со списком нарушений, нехватка курсов валют — со списком недостающих курсов.
+func WriteError(w http.ResponseWriter, r *http.Request, err error, detail string) {
+	var verr *dto.ValidationError
+	if errors.As(err, &verr) {
+		WriteValidation(w, r, verr)
+		return
+	}
+
+	code, k, ok := lookup(err)
+	if !ok {
+		Write(w, r, http.StatusInternalServerError, detail)
+		return
+	}
+
+	lang := i18n.FromRequest(r)
+	p := &dto.Problem{
+		Type:   typeURI(code),
+		Title:  i18n.Text(lang, k.title),
+		Status: k.status,
+		Code:   string(code),
+		Detail: i18n.Text(lang, k.detail),
+	}
+	var missingRates *models.MissingRatesError
+	if errors.As(err, &missingRates) {
+		p.MissingRates = dto.ToMissingRates(missingRates)
+	}
+	write(w, r, lang, p)
+}
+
+// WriteValidation отвечает 422 со списком нарушений правил валидации.
+func WriteValidation(w http.ResponseWriter, r *http.Request, verr *dto.ValidationError) {
+	lang := i18n.FromRequest(r)
+	write(w, r, lang, &dto.Problem{
+		Type:   "/problems/validation",
+		Title:  i18n.Text(lang, "Validation failed"),
+		Status: http.StatusUnprocessableEntity,
+		Errors: verr.Localized(lang),
+	})
+}
+
+func write(w http.ResponseWriter, r *http.Request, lang i18n.Lang, p *dto.Problem) {
+	p.Instance = r.URL.Path
+	p.RequestID = logger.RequestID(r.Context())
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Content-Language", string(lang))
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		logger.FromContext(r.Context()).Error("ошибка записи описания ошибки", "error", err)
+	}
+}
@@ -0,0 +1,118 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AntonTsoy/subscription-service/internal/i18n"
+	"github.com/AntonTsoy/subscription-service/internal/logger"
+	"github.com/AntonTsoy/subscription-service/internal/models"
+	"github.com/AntonTsoy/subscription-service/internal/transport/dto"
+)
+
+func TestWriteError(t *testing.T) {
+	missing := models.NewMissingRatesError([]models.CurrencyMonth{{Currency: "USD", Month: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)}})
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		typ     string
+		code    string
+		detail  string
+		missing int
+	}{
+		{"domain error", fmt.Errorf("%w: подписка id 7", models.ErrSubscriptionNotFound), http.StatusNotFound, "/problems/subscription-not-found", "SUBSCRIPTION_NOT_FOUND", "subscription with this id does not exist", 0},
+		{"translated db error", fmt.Errorf("не удалось записать данные подписки: %w", models.ErrDuplicateSubscription.Wrap(errors.New("pq: duplicate key"))), http.StatusConflict, "/problems/duplicate-subscription", "DUPLICATE_SUBSCRIPTION", "user already has a subscription to this service with the same start_date", 0},
+		{"missing rates", fmt.Errorf("ошибка расчёта стоимости: %w", missing), http.StatusUnprocessableEntity, "/problems/exchange-rate-missing", "EXCHANGE_RATE_MISSING", "exchange rates listed in missing_rates are required for conversion", 1},
+		{"internal error", errors.New("соединение закрыто"), http.StatusInternalServerError, "about:blank", "", "failed to get subscription", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/subscriptions/7", nil)
+			req = req.WithContext(logger.WithRequestID(req.Context(), slog.Default(), "req-1"))
+			rec := httptest.NewRecorder()
+
+			WriteError(rec, req, tt.err, "failed to get subscription")
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Content-Type"); got != ContentType {
+				t.Errorf("Content-Type = %q, want %q", got, ContentType)
+			}
+			var p dto.Problem
+			if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+				t.Fatalf("тело ответа не JSON: %v", err)
+			}
+			if p.Type != tt.typ || p.Status != tt.status || p.Code != tt.code || p.Detail != tt.detail || len(p.MissingRates) != tt.missing {
+				t.Errorf("problem = %+v", p)
+			}
+			if p.Instance != "/subscriptions/7" || p.RequestID != "req-1" {
+				t.Errorf("instance = %q, request_id = %q", p.Instance, p.RequestID)
+			}
+		})
+	}
+}
+
+func TestCatalogDetails(t *testing.T) {
+	for code, k := range catalog {
+		if k.detail == "" {
+			t.Errorf("%s: detail is empty", code)
+		}
+		for _, msg := range []string{k.title, k.detail} {
+			if i18n.Text(i18n.Russian, msg) == msg {
+				t.Errorf("%s: нет русского перевода для %q", code, msg)
+			}
+		}
+	}
+}
+
+func TestWriteErrorValidation(t *testing.T) {
+	verr := &dto.ValidationError{Errors: []dto.FieldError{{Field: "price", Code: dto.CodeMin, Message: "price must not be negative"}}}
+	rec := httptest.NewRecorder()
+
+	WriteError(rec, httptest.NewRequest(http.MethodPatch, "/subscriptions/1", nil), fmt.Errorf("%w: %w", models.ErrInvalidPatch, verr), "")
+
+	var p dto.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("тело ответа не JSON: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity || len(p.Errors) != 1 || p.Errors[0].Field != "price" {
+		t.Errorf("status = %d, problem = %+v", rec.Code, p)
+	}
+}
+
+func TestWriteLocalized(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions/abc", nil)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	rec := httptest.NewRecorder()
+
+	Write(rec, req, http.StatusBadRequest, "missing or invalid subscription id path parameter value")
+
+	var p dto.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("тело ответа не JSON: %v", err)
+	}
+	if p.Title != "Некорректный запрос" || p.Detail != "id подписки в пути запроса не передан или некорректен" {
+		t.Errorf("problem = %+v, want Russian title and detail", p)
+	}
+	if got := rec.Header().Get("Content-Language"); got != "ru" {
+		t.Errorf("Content-Language = %q, want ru", got)
+	}
+}
+
+func TestCatalogTitlesTranslated(t *testing.T) {
+	for code, k := range catalog {
+		if i18n.Text(i18n.Russian, k.title) == k.title {
+			t.Errorf("нет перевода заголовка %q для %s", k.title, code)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    price INTEGER NOT NULL,
+    effective_from DATE NOT NULL,
+    UNIQUE (subscription_id, effective_from)
+);
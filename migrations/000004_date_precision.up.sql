@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions
+    ADD COLUMN IF NOT EXISTS date_precision TEXT NOT NULL DEFAULT 'month'
+        CHECK (date_precision IN ('month', 'day'));
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS subscription_discounts (
+    id SERIAL PRIMARY KEY,
+    subscription_id INTEGER NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value INTEGER NOT NULL CHECK (value > 0),
+    start_month DATE NOT NULL,
+    end_month DATE,
+    CHECK (kind <> 'percent' OR value <= 100),
+    CHECK (end_month IS NULL OR end_month >= start_month)
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscription_discounts_subscription_id ON subscription_discounts (subscription_id);
@@ -0,0 +1,5 @@
+ALTER TABLE subscriptions
+    DROP CONSTRAINT IF EXISTS subscriptions_price_check,
+    DROP CONSTRAINT IF EXISTS subscriptions_trial_end_check,
+    DROP CONSTRAINT IF EXISTS subscriptions_trial_period_check,
+    DROP CONSTRAINT IF EXISTS subscriptions_period_check;
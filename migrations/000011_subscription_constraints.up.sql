@@ -0,0 +1,20 @@
+-- NOT VALID: ограничения проверяются для новых и изменённых строк, уже
+-- записанные данные не мешают применить миграцию.
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_period_check
+        CHECK (end_date IS NULL OR end_date >= start_date) NOT VALID,
+    ADD CONSTRAINT subscriptions_trial_period_check
+        CHECK (trial_end_date IS NULL OR trial_end_date >= start_date) NOT VALID,
+    ADD CONSTRAINT subscriptions_trial_end_check
+        CHECK (trial_end_date IS NULL OR end_date IS NULL OR trial_end_date <= end_date) NOT VALID,
+    ADD CONSTRAINT subscriptions_price_check
+        CHECK (price >= 0) NOT VALID;
+
+-- Дубли (тот же пользователь, сервис и дата начала) мешают создать уникальный
+-- индекс в миграции 000012: остаётся подписка с наименьшим id.
+DELETE FROM subscriptions s
+USING subscriptions d
+WHERE s.user_id = d.user_id
+    AND s.service_name = d.service_name
+    AND s.start_date = d.start_date
+    AND s.id > d.id;
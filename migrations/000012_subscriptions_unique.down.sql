@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS subscriptions_user_service_start_key;
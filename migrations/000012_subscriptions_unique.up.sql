@@ -0,0 +1,2 @@
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS subscriptions_user_service_start_key
+    ON subscriptions (user_id, service_name, start_date);